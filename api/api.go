@@ -1,12 +1,24 @@
 package api
 
 import (
+	"fmt"
+
+	"github.com/Forum-service/Forum-api-gateway/api/authz"
+	"github.com/Forum-service/Forum-api-gateway/api/cache"
 	_ "github.com/Forum-service/Forum-api-gateway/api/docs"
 	"github.com/Forum-service/Forum-api-gateway/api/handler"
+	"github.com/Forum-service/Forum-api-gateway/api/idempotency"
+	"github.com/Forum-service/Forum-api-gateway/api/middlewares"
+	"github.com/Forum-service/Forum-api-gateway/api/ratelimit"
+	"github.com/Forum-service/Forum-api-gateway/api/tokens"
+	"github.com/Forum-service/Forum-api-gateway/config"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/rs/zerolog/log"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
 // @title Forum API Gateway
@@ -17,60 +29,169 @@ import (
 // @securityDefinitions.apikey BearerAuth
 // @in header
 // @name Authorization
-func NewEngine() *gin.Engine {
-	h, err := handler.NewHandler()
+func NewEngine(cfg config.Config, h *handler.Handler) *gin.Engine {
+	keys, err := newKeySource(cfg)
+	if err != nil {
+		panic(err)
+	}
+	verifier, err := tokens.NewVerifier(keys, tokens.Options{
+		Algorithms: cfg.JWTAlgorithms,
+		Issuer:     cfg.JWTIssuer,
+		Audience:   cfg.JWTAudience,
+		ClockSkew:  cfg.JWTClockSkew,
+	})
 	if err != nil {
 		panic(err)
 	}
 
-	r := gin.Default()
+	policy, err := authz.LoadPolicy(cfg.AuthzPolicyFile)
+	if err != nil {
+		panic(err)
+	}
+
+	r := gin.New()
+	if err := r.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		panic(err)
+	}
+	r.Use(
+		otelgin.Middleware(cfg.Tracing.ServiceName),
+		middlewares.RequestID(),
+		middlewares.AccessLog(),
+		// Metrics wraps Recovery so panics are counted as the 500 they
+		// are turned into.
+		middlewares.Metrics(),
+		gin.Recovery(),
+	)
+	r.GET("/metrics", gin.WrapH(promhttp.Handler()))
 	r.GET("/api/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
-	// r.Use(middlewares.Auth)
+	r.GET("/healthz", h.Healthz)
+	r.GET("/readyz", h.Readyz)
+	r.GET("/status", h.Status)
+	r.GET("/status/breakers", h.BreakerStatus)
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"*"}, // Adjust for your specific origins
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "If-None-Match", "If-Match", "Idempotency-Key", "X-Request-ID", "traceparent", "tracestate"},
+		ExposeHeaders:    []string{"Content-Length", "X-Request-ID", "Link", "ETag", "X-Cache", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "RateLimit-Policy", "Retry-After", "Idempotent-Replayed"},
 		AllowCredentials: true,
 	}))
+	owners := authz.Owners{
+		"post":    h.PostOwner,
+		"comment": h.CommentOwner,
+		"posttag": h.PostTagOwner,
+	}
+	chain := []gin.HandlerFunc{middlewares.Auth(verifier)}
+	if cfg.RateLimit.Enabled {
+		rules, err := ratelimit.ParseRules(cfg.RateLimit.Read, cfg.RateLimit.Write, cfg.RateLimit.Routes)
+		if err != nil {
+			panic(err)
+		}
+		client, err := ratelimit.ParseLimit(cfg.RateLimit.Client)
+		if err != nil {
+			panic(err)
+		}
+		store := ratelimit.NewMemoryStore()
+		chain = []gin.HandlerFunc{
+			middlewares.ClientRateLimit(store, client, cfg.RateLimit.APIKeyHeader),
+			middlewares.Auth(verifier),
+			middlewares.RateLimit(store, rules, cfg.RateLimit.APIKeyHeader),
+		}
+	}
+	chain = append(chain, middlewares.Authorize(policy, owners), middlewares.ForwardMetadata())
+	if cfg.Idempotency.Enabled {
+		chain = append(chain, middlewares.Idempotency(idempotency.NewMemoryStore(), cfg.Idempotency.TTL))
+	}
+
+	// Cached responses are invalidated by writes through this replica only,
+	// others serve theirs until the TTL expires.
+	var rc *middlewares.ResponseCache
+	if cfg.Cache.Enabled {
+		rc = middlewares.NewResponseCache(cache.New(cfg.Cache.MaxEntries), cfg.Cache.TTLs)
+	}
+
 	// Grouping API routes under /v1
-	v1 := r.Group("/v1")
+	v1 := r.Group("/v1", chain...)
 	{
 		// Categories
-		v1.POST("/categories", h.CreateCategory)
+		v1.POST("/categories", rc.Invalidates("categories"), h.CreateCategory)
 		v1.GET("/categories/:id", h.GetCategoryById)
-		v1.PUT("/categories", h.UpdateCategory)
-		v1.DELETE("/categories/:id", h.DeleteCategory)
-		v1.GET("/categories", h.GetAllCategories)
+		v1.PUT("/categories", rc.Invalidates("categories"), h.UpdateCategory)
+		v1.PATCH("/categories/:id", rc.Invalidates("categories"), h.PatchCategory)
+		v1.DELETE("/categories/:id", rc.Invalidates("categories"), h.DeleteCategory)
+		v1.GET("/categories", rc.Cached("categories"), h.GetAllCategories)
+		v1.GET("/categories/:id/posts", h.GetCategoryPosts)
 
 		// Tags
-		v1.POST("/tags", h.CreateTag)
+		v1.POST("/tags", rc.Invalidates("tags"), h.CreateTag)
 		v1.GET("/tags/:id", h.GetTagById)
-		v1.PUT("/tags/:id", h.UpdateTag)
-		v1.DELETE("/tags/:id", h.DeleteTag)
+		v1.PUT("/tags/:id", rc.Invalidates("tags"), h.UpdateTag)
+		v1.PATCH("/tags/:id", rc.Invalidates("tags"), h.PatchTag)
+		v1.DELETE("/tags/:id", rc.Invalidates("tags"), h.DeleteTag)
 		v1.GET("/tags", h.GetAllTags)
 
 		// Posts
 		v1.POST("/posts", h.CreatePost)
-		v1.GET("/posts/:id", h.GetPostById)
-		v1.PUT("/posts/:id", h.UpdatePost)
-		v1.DELETE("/posts/:id", h.DeletePost)
+		v1.GET("/posts/:id", rc.Cached("post:{id}"), h.GetPostById)
+		v1.PUT("/posts/:id", rc.Invalidates("post:{id}"), h.UpdatePost)
+		v1.PATCH("/posts/:id", rc.Invalidates("post:{id}"), h.PatchPost)
+		v1.DELETE("/posts/:id", rc.Invalidates("post:{id}", "tags"), h.DeletePost)
 		v1.GET("/posts", h.GetAllPosts)
+		v1.GET("/posts/:id/full", h.GetPostFull)
+		v1.GET("/posts/:id/comments", h.GetPostComments)
+		v1.GET("/posts/:id/comments/tree", h.GetPostCommentTree)
+		v1.GET("/posts/:id/tags", h.GetPostTags)
+		v1.POST("/posts/:id/tags", rc.Invalidates("tags"), h.AddPostTag)
+		v1.DELETE("/posts/:id/tags/:tag_id", rc.Invalidates("tags"), h.RemovePostTag)
+
+		// Users
+		v1.GET("/users/:id/posts", h.GetUserPosts)
 
 		// Comments
 		v1.POST("/comments", h.CreateComment)
 		v1.GET("/comments/:id", h.GetCommentById)
 		v1.PUT("/comments/:id", h.UpdateComment)
+		v1.PATCH("/comments/:id", h.PatchComment)
 		v1.DELETE("/comments/:id", h.DeleteComment)
 		v1.GET("/comments", h.GetAllComments)
+		v1.GET("/comments/:id/replies", h.GetCommentReplies)
 
 		// PostTags
-		v1.POST("/posttags", h.CreatePostTag)
-		v1.DELETE("/posttags/:postid/:tagid", h.DeletePostTag)
+		v1.POST("/posttags", rc.Invalidates("tags"), h.CreatePostTag)
+		v1.DELETE("/posttags/:postid/:tagid", rc.Invalidates("tags"), h.DeletePostTag)
 		v1.GET("/posttags", h.GetAllPostTags)
 		v1.GET("/posttags/:tag_id/posts", h.GetPostsByTag)
-		v1.GET("/tags/popular", h.GetFamousTags)
+		v1.GET("/tags/popular", rc.Cached("tags"), h.GetFamousTags)
+
+		// Search
+		v1.GET("/search", h.Search)
 	}
 
 	return r
 }
+
+// newKeySource builds the token key source from the static keys and the
+// JWKS endpoint, whichever are configured.
+func newKeySource(cfg config.Config) (tokens.KeySource, error) {
+	var sources tokens.KeySources
+
+	if cfg.JWKSURL != "" {
+		jwks, err := tokens.NewJWKS(cfg.JWKSURL, tokens.JWKSOptions{
+			RefreshInterval:    cfg.JWKSRefreshInterval,
+			MinRefreshInterval: cfg.JWKSMinRefreshInterval,
+		})
+		if err != nil {
+			log.Warn().Err(err).Str("url", cfg.JWKSURL).Msg("initial jwks fetch failed")
+		}
+		sources = append(sources, jwks)
+	}
+
+	if cfg.JWTSecret != "" || len(cfg.JWTPublicKeyFiles) > 0 || len(sources) == 0 {
+		static, err := tokens.LoadStaticKeys(cfg.JWTSecret, cfg.JWTPublicKeyFiles)
+		if err != nil {
+			return nil, fmt.Errorf("%v: set JWT_SECRET, JWT_PUBLIC_KEY_FILES or JWKS_URL, see .env.example", err)
+		}
+		sources = append(sources, static)
+	}
+
+	return sources, nil
+}
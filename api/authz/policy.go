@@ -0,0 +1,98 @@
+package authz
+
+import (
+	_ "embed"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed policies.yaml
+var defaultPolicies []byte
+
+// Rule describes what a caller needs to access a route.
+type Rule struct {
+	Method string   `yaml:"method"`
+	Path   string   `yaml:"path"`
+	AnyOf  []string `yaml:"any_of"`
+	Owner  string   `yaml:"owner"`
+}
+
+// Policy is a table of rules keyed by method and route template.
+type Policy struct {
+	Rules []Rule `yaml:"rules"`
+
+	index map[string]Rule
+}
+
+// OwnerFunc returns the id of the user owning the resource addressed by
+// the request. An OwnerFunc may abort the request with its own response,
+// e.g. for an unreadable body; otherwise its error is answered as a gRPC
+// error.
+type OwnerFunc func(c *gin.Context) (string, error)
+
+// Owners maps a resource kind used in Rule.Owner to its OwnerFunc.
+type Owners map[string]OwnerFunc
+
+// LoadPolicy reads the policy table from a YAML file. An empty path loads
+// the built-in policies.
+func LoadPolicy(path string) (*Policy, error) {
+	data := defaultPolicies
+	if path != "" {
+		var err error
+		data, err = os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("error reading policy file: %v", err)
+		}
+	}
+	return ParsePolicy(data)
+}
+
+// ParsePolicy parses and validates a YAML policy table.
+func ParsePolicy(data []byte) (*Policy, error) {
+	var p Policy
+	if err := yaml.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("error parsing policy: %v", err)
+	}
+
+	p.index = make(map[string]Rule, len(p.Rules))
+	for i, r := range p.Rules {
+		r.Method = strings.ToUpper(r.Method)
+		if r.Method == "" || r.Path == "" {
+			return nil, fmt.Errorf("policy rule %d: method and path are required", i)
+		}
+		if r.Method != "*" && !isHTTPMethod(r.Method) {
+			return nil, fmt.Errorf("policy rule %d: unknown method %q", i, r.Method)
+		}
+		key := r.Method + " " + r.Path
+		if _, ok := p.index[key]; ok {
+			return nil, fmt.Errorf("policy rule %d: duplicate rule for %s", i, key)
+		}
+		p.index[key] = r
+	}
+
+	return &p, nil
+}
+
+// Rule returns the rule for a route, preferring an exact method match over
+// a "*" rule.
+func (p *Policy) Rule(method, path string) (Rule, bool) {
+	if r, ok := p.index[method+" "+path]; ok {
+		return r, true
+	}
+	r, ok := p.index["* "+path]
+	return r, ok
+}
+
+func isHTTPMethod(m string) bool {
+	switch m {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+		http.MethodPatch, http.MethodDelete, http.MethodOptions:
+		return true
+	}
+	return false
+}
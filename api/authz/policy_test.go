@@ -0,0 +1,91 @@
+package authz
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt"
+)
+
+func TestParsePolicy(t *testing.T) {
+	p, err := ParsePolicy([]byte(`
+rules:
+  - method: put
+    path: /v1/posts/:id
+    any_of: [forum:moderator]
+    owner: post
+  - method: "*"
+    path: /v1/posts/:id
+    any_of: [forum:admin]
+`))
+	if err != nil {
+		t.Fatalf("ParsePolicy: %v", err)
+	}
+
+	r, ok := p.Rule("PUT", "/v1/posts/:id")
+	if !ok || r.Owner != "post" || len(r.AnyOf) != 1 || r.AnyOf[0] != "forum:moderator" {
+		t.Errorf("Rule(PUT) = %+v, %v; want the PUT rule", r, ok)
+	}
+	if r, ok := p.Rule("DELETE", "/v1/posts/:id"); !ok || r.AnyOf[0] != "forum:admin" {
+		t.Errorf("Rule(DELETE) = %+v, %v; want the * rule", r, ok)
+	}
+	if _, ok := p.Rule("GET", "/v1/posts"); ok {
+		t.Error("Rule returned a rule for an unlisted route")
+	}
+}
+
+func TestParsePolicyRejectsInvalidRules(t *testing.T) {
+	tests := map[string]string{
+		"missing path":   "rules:\n  - method: GET\n",
+		"missing method": "rules:\n  - path: /v1/posts\n",
+		"unknown method": "rules:\n  - method: FETCH\n    path: /v1/posts\n",
+		"duplicate":      "rules:\n  - method: GET\n    path: /v1/posts\n  - method: get\n    path: /v1/posts\n",
+		"not yaml":       "rules: [",
+	}
+	for name, data := range tests {
+		if _, err := ParsePolicy([]byte(data)); err == nil {
+			t.Errorf("%s: ParsePolicy accepted %q", name, data)
+		}
+	}
+}
+
+func TestLoadPolicyDefault(t *testing.T) {
+	p, err := LoadPolicy("")
+	if err != nil {
+		t.Fatalf("LoadPolicy: %v", err)
+	}
+	for _, route := range []string{"PUT /v1/posts/:id", "PATCH /v1/comments/:id", "POST /v1/posttags"} {
+		method, path, _ := strings.Cut(route, " ")
+		if r, ok := p.Rule(method, path); !ok || r.Owner == "" {
+			t.Errorf("built-in policy has no owner rule for %s", route)
+		}
+	}
+}
+
+func TestFromClaims(t *testing.T) {
+	p := FromClaims(jwt.MapClaims{
+		"sub":   "u1",
+		"roles": []interface{}{"forum:moderator"},
+		"role":  "forum:user",
+		"scope": "posts:write comments:write",
+		"scp":   []interface{}{"tags:write"},
+	})
+	if p.UserID != "u1" {
+		t.Errorf("UserID = %q, want u1", p.UserID)
+	}
+	for _, perm := range []string{"forum:moderator", "forum:user", "posts:write", "comments:write", "tags:write"} {
+		if !p.Has(perm) {
+			t.Errorf("principal lacks %s", perm)
+		}
+	}
+	if p.HasAny([]string{"forum:admin"}) {
+		t.Error("HasAny(forum:admin) = true")
+	}
+	if !p.HasAny([]string{"forum:admin", "tags:write"}) {
+		t.Error("HasAny(forum:admin, tags:write) = false")
+	}
+
+	if p := FromClaims(jwt.MapClaims{"user_id": "u2"}); p.UserID != "u2" {
+		t.Errorf("UserID from user_id = %q, want u2", p.UserID)
+	}
+}
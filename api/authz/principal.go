@@ -0,0 +1,83 @@
+package authz
+
+import (
+	"strings"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// Principal is the authenticated caller as described by its token claims.
+type Principal struct {
+	UserID      string
+	Roles       []string
+	Scopes      []string
+	permissions map[string]bool
+}
+
+// FromClaims builds a Principal from validated token claims. The user id is
+// read from sub (falling back to user_id), roles from roles or role and
+// scopes from the space separated scope claim or the scp list.
+func FromClaims(claims jwt.MapClaims) Principal {
+	p := Principal{
+		UserID: firstString(claims, "sub", "user_id"),
+		Roles:  append(stringList(claims["roles"]), stringList(claims["role"])...),
+		Scopes: stringList(claims["scp"]),
+	}
+	if scope, ok := claims["scope"].(string); ok {
+		p.Scopes = append(p.Scopes, strings.Fields(scope)...)
+	}
+
+	p.permissions = make(map[string]bool, len(p.Roles)+len(p.Scopes))
+	for _, r := range p.Roles {
+		p.permissions[r] = true
+	}
+	for _, s := range p.Scopes {
+		p.permissions[s] = true
+	}
+	return p
+}
+
+// Has reports whether the principal holds the role or scope perm.
+func (p Principal) Has(perm string) bool {
+	return p.permissions[perm]
+}
+
+// HasAny reports whether the principal holds at least one of perms.
+func (p Principal) HasAny(perms []string) bool {
+	for _, perm := range perms {
+		if p.Has(perm) {
+			return true
+		}
+	}
+	return false
+}
+
+func firstString(claims jwt.MapClaims, names ...string) string {
+	for _, name := range names {
+		if s, ok := claims[name].(string); ok && s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+func stringList(v interface{}) []string {
+	switch v := v.(type) {
+	case string:
+		if v == "" {
+			return nil
+		}
+		return []string{v}
+	case []string:
+		return v
+	case []interface{}:
+		list := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				list = append(list, s)
+			}
+		}
+		return list
+	}
+	return nil
+}
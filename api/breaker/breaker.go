@@ -0,0 +1,216 @@
+package breaker
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// State is the state of a Breaker.
+type State int
+
+const (
+	Closed State = iota
+	HalfOpen
+	Open
+)
+
+func (s State) String() string {
+	switch s {
+	case Closed:
+		return "closed"
+	case HalfOpen:
+		return "half-open"
+	case Open:
+		return "open"
+	}
+	return "unknown"
+}
+
+// ErrOpen is returned by Allow while the breaker rejects calls.
+var ErrOpen = errors.New("circuit breaker is open")
+
+// Settings configures a Breaker.
+type Settings struct {
+	// FailureRatio trips the breaker once failures/requests reaches it.
+	FailureRatio float64
+	// MinRequests is the number of requests in the window needed before
+	// the ratio is evaluated.
+	MinRequests int
+	// Interval is the length of the counting window while closed.
+	Interval time.Duration
+	// CoolDown is how long the breaker stays open before probing.
+	CoolDown time.Duration
+	// HalfOpenMaxRequests is the number of probe calls let through while
+	// half-open; all of them must succeed to close the breaker.
+	HalfOpenMaxRequests int
+}
+
+// Counts are the request counters of the current window.
+type Counts struct {
+	Requests  int `json:"requests"`
+	Failures  int `json:"failures"`
+	Successes int `json:"successes"`
+}
+
+// Snapshot describes a Breaker at a point in time.
+type Snapshot struct {
+	Name        string     `json:"name"`
+	State       string     `json:"state"`
+	Counts      Counts     `json:"counts"`
+	OpenedAt    *time.Time `json:"opened_at,omitempty"`
+	Rejected    uint64     `json:"rejected"`
+	Transitions uint64     `json:"transitions"`
+}
+
+// Breaker is a circuit breaker with closed, open and half-open states.
+type Breaker struct {
+	name     string
+	settings Settings
+	now      func() time.Time
+
+	mu          sync.Mutex
+	state       State
+	counts      Counts
+	windowStart time.Time
+	openedAt    time.Time
+	inFlight    int
+	generation  uint64
+	rejected    uint64
+	transitions uint64
+	onChange    func(name string, from, to State)
+}
+
+// New returns a closed Breaker.
+func New(name string, settings Settings) *Breaker {
+	if settings.HalfOpenMaxRequests <= 0 {
+		settings.HalfOpenMaxRequests = 1
+	}
+	b := &Breaker{name: name, settings: settings, now: time.Now}
+	b.windowStart = b.now()
+	return b
+}
+
+// Name returns the breaker name.
+func (b *Breaker) Name() string {
+	return b.name
+}
+
+// Allow reports whether a call may proceed. On success the caller must
+// report the outcome through done. While open it returns ErrOpen and the
+// time left until the breaker probes again.
+func (b *Breaker) Allow() (done func(success bool), retryAfter time.Duration, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	b.advance(now)
+
+	switch b.state {
+	case Open:
+		b.rejected++
+		return nil, b.openedAt.Add(b.settings.CoolDown).Sub(now), ErrOpen
+	case HalfOpen:
+		if b.inFlight >= b.settings.HalfOpenMaxRequests {
+			b.rejected++
+			return nil, time.Second, ErrOpen
+		}
+	}
+
+	b.inFlight++
+	gen := b.generation
+	return func(success bool) { b.report(gen, success) }, 0, nil
+}
+
+// State returns the current state.
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.advance(b.now())
+	return b.state
+}
+
+// Snapshot returns the current state and counters.
+func (b *Breaker) Snapshot() Snapshot {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.advance(b.now())
+
+	s := Snapshot{
+		Name:        b.name,
+		State:       b.state.String(),
+		Counts:      b.counts,
+		Rejected:    b.rejected,
+		Transitions: b.transitions,
+	}
+	if b.state != Closed {
+		openedAt := b.openedAt
+		s.OpenedAt = &openedAt
+	}
+	return s
+}
+
+func (b *Breaker) report(gen uint64, success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// Ignore outcomes of calls started in an earlier state; they are no
+	// longer counted as in flight either.
+	if gen != b.generation {
+		return
+	}
+	b.inFlight--
+
+	b.counts.Requests++
+	if success {
+		b.counts.Successes++
+	} else {
+		b.counts.Failures++
+	}
+
+	switch b.state {
+	case Closed:
+		if b.counts.Requests >= b.settings.MinRequests &&
+			float64(b.counts.Failures)/float64(b.counts.Requests) >= b.settings.FailureRatio {
+			b.setState(Open, b.now())
+		}
+	case HalfOpen:
+		if !success {
+			b.setState(Open, b.now())
+		} else if b.counts.Successes >= b.settings.HalfOpenMaxRequests {
+			b.setState(Closed, b.now())
+		}
+	}
+}
+
+// advance moves an open breaker to half-open after the cool-down and
+// starts a new counting window when the interval elapsed.
+func (b *Breaker) advance(now time.Time) {
+	switch b.state {
+	case Open:
+		if !now.Before(b.openedAt.Add(b.settings.CoolDown)) {
+			b.setState(HalfOpen, now)
+		}
+	case Closed:
+		if b.settings.Interval > 0 && !now.Before(b.windowStart.Add(b.settings.Interval)) {
+			b.counts = Counts{}
+			b.windowStart = now
+		}
+	}
+}
+
+func (b *Breaker) setState(to State, now time.Time) {
+	from := b.state
+	b.state = to
+	b.counts = Counts{}
+	b.windowStart = now
+	b.inFlight = 0
+	b.generation++
+	b.transitions++
+	if to == Open {
+		b.openedAt = now
+	}
+	if b.onChange != nil {
+		b.onChange(b.name, from, to)
+	}
+}
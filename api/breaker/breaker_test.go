@@ -0,0 +1,224 @@
+package breaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+var testSettings = Settings{
+	FailureRatio:        0.5,
+	MinRequests:         4,
+	Interval:            time.Minute,
+	CoolDown:            10 * time.Second,
+	HalfOpenMaxRequests: 2,
+}
+
+// newTestBreaker returns a breaker whose clock only moves through the
+// returned advance function.
+func newTestBreaker(settings Settings) (*Breaker, func(time.Duration)) {
+	b := New("svc", settings)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	b.now = func() time.Time { return now }
+	b.windowStart = now
+	return b, func(d time.Duration) { now = now.Add(d) }
+}
+
+// call runs one call through b and reports success.
+func call(t *testing.T, b *Breaker, success bool) {
+	t.Helper()
+	done, _, err := b.Allow()
+	if err != nil {
+		t.Fatalf("Allow in state %s: %v", b.State(), err)
+	}
+	done(success)
+}
+
+// trip opens b with failing calls.
+func trip(t *testing.T, b *Breaker) {
+	t.Helper()
+	for i := 0; i < b.settings.MinRequests; i++ {
+		call(t, b, false)
+	}
+	if s := b.State(); s != Open {
+		t.Fatalf("state after %d failures = %s, want open", b.settings.MinRequests, s)
+	}
+}
+
+func TestBreakerTripsOnFailureRatio(t *testing.T) {
+	tests := []struct {
+		name     string
+		outcomes []bool
+		want     State
+	}{
+		{"below min requests", []bool{false, false, false}, Closed},
+		{"ratio below threshold", []bool{true, true, true, false}, Closed},
+		{"ratio at threshold", []bool{true, false, true, false}, Open},
+		{"all failures", []bool{false, false, false, false}, Open},
+		{"threshold after min requests", []bool{true, true, true, true, false, false, false, false}, Open},
+	}
+	for _, tt := range tests {
+		b, _ := newTestBreaker(testSettings)
+		for _, ok := range tt.outcomes {
+			call(t, b, ok)
+		}
+		if got := b.State(); got != tt.want {
+			t.Errorf("%s: state = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBreakerWindowResetsCounts(t *testing.T) {
+	b, advance := newTestBreaker(testSettings)
+	for i := 0; i < 3; i++ {
+		call(t, b, false)
+	}
+	advance(time.Minute)
+	call(t, b, false)
+	if got := b.State(); got != Closed {
+		t.Fatalf("state = %s, want closed: failures of the previous window were counted", got)
+	}
+	if got := b.Snapshot().Counts; got != (Counts{Requests: 1, Failures: 1}) {
+		t.Errorf("counts = %+v, want one failure", got)
+	}
+}
+
+func TestBreakerCoolDown(t *testing.T) {
+	tests := []struct {
+		name      string
+		elapsed   time.Duration
+		want      State
+		wantRetry time.Duration
+	}{
+		{"just opened", 0, Open, 10 * time.Second},
+		{"cooling down", 9 * time.Second, Open, time.Second},
+		{"cool-down elapsed", 10 * time.Second, HalfOpen, 0},
+		{"long after", time.Hour, HalfOpen, 0},
+	}
+	for _, tt := range tests {
+		b, advance := newTestBreaker(testSettings)
+		trip(t, b)
+		advance(tt.elapsed)
+
+		if got := b.State(); got != tt.want {
+			t.Errorf("%s: state = %s, want %s", tt.name, got, tt.want)
+		}
+		_, retryAfter, err := b.Allow()
+		if tt.want == Open {
+			if !errors.Is(err, ErrOpen) || retryAfter != tt.wantRetry {
+				t.Errorf("%s: Allow = %v, %v, want ErrOpen, %v", tt.name, retryAfter, err, tt.wantRetry)
+			}
+		} else if err != nil {
+			t.Errorf("%s: Allow: %v", tt.name, err)
+		}
+	}
+}
+
+func TestBreakerHalfOpenProbes(t *testing.T) {
+	tests := []struct {
+		name     string
+		outcomes []bool
+		want     State
+	}{
+		{"all probes succeed", []bool{true, true}, Closed},
+		{"first probe fails", []bool{false, true}, Open},
+		{"last probe fails", []bool{true, false}, Open},
+		{"probes pending", []bool{true}, HalfOpen},
+	}
+	for _, tt := range tests {
+		b, advance := newTestBreaker(testSettings)
+		trip(t, b)
+		advance(testSettings.CoolDown)
+
+		var probes []func(bool)
+		for i := 0; i < testSettings.HalfOpenMaxRequests; i++ {
+			done, _, err := b.Allow()
+			if err != nil {
+				t.Fatalf("%s: probe %d: %v", tt.name, i, err)
+			}
+			probes = append(probes, done)
+		}
+		if _, _, err := b.Allow(); !errors.Is(err, ErrOpen) {
+			t.Errorf("%s: Allow beyond the probe limit: err = %v, want ErrOpen", tt.name, err)
+		}
+
+		for i, ok := range tt.outcomes {
+			probes[i](ok)
+		}
+		if got := b.State(); got != tt.want {
+			t.Errorf("%s: state = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBreakerIgnoresStaleReports(t *testing.T) {
+	tests := []struct {
+		name    string
+		success bool
+		// prepare moves the breaker on from the state the stale call
+		// started in.
+		prepare func(t *testing.T, b *Breaker, advance func(time.Duration))
+		want    State
+	}{
+		{
+			name:    "closed call succeeding while half-open",
+			success: true,
+			prepare: func(t *testing.T, b *Breaker, advance func(time.Duration)) {
+				trip(t, b)
+				advance(testSettings.CoolDown)
+			},
+			want: HalfOpen,
+		},
+		{
+			name:    "closed call failing while half-open",
+			success: false,
+			prepare: func(t *testing.T, b *Breaker, advance func(time.Duration)) {
+				trip(t, b)
+				advance(testSettings.CoolDown)
+			},
+			want: HalfOpen,
+		},
+		{
+			name:    "closed call failing after the breaker closed again",
+			success: false,
+			prepare: func(t *testing.T, b *Breaker, advance func(time.Duration)) {
+				trip(t, b)
+				advance(testSettings.CoolDown)
+				call(t, b, true)
+				call(t, b, true)
+				for i := 0; i < 3; i++ {
+					call(t, b, false)
+				}
+			},
+			want: Closed,
+		},
+	}
+	for _, tt := range tests {
+		b, advance := newTestBreaker(testSettings)
+		stale, _, err := b.Allow()
+		if err != nil {
+			t.Fatal(err)
+		}
+		tt.prepare(t, b, advance)
+		stale(tt.success)
+
+		if got := b.State(); got != tt.want {
+			t.Errorf("%s: state = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBreakerStaleCallsDoNotUseProbeSlots(t *testing.T) {
+	b, advance := newTestBreaker(Settings{FailureRatio: 1, MinRequests: 1, CoolDown: time.Second, HalfOpenMaxRequests: 1})
+	// A slow call started while closed is still running when the breaker
+	// becomes half-open.
+	if _, _, err := b.Allow(); err != nil {
+		t.Fatal(err)
+	}
+	call(t, b, false)
+	advance(time.Second)
+
+	if _, _, err := b.Allow(); err != nil {
+		t.Errorf("probe while a closed-state call is in flight: %v", err)
+	}
+}
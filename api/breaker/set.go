@@ -0,0 +1,59 @@
+package breaker
+
+import (
+	"sort"
+	"sync"
+)
+
+// Set holds one Breaker per name, created on first use with shared settings.
+type Set struct {
+	settings Settings
+	onChange func(name string, from, to State)
+
+	mu       sync.Mutex
+	breakers map[string]*Breaker
+}
+
+// NewSet returns an empty Set.
+func NewSet(settings Settings) *Set {
+	return &Set{settings: settings, breakers: map[string]*Breaker{}}
+}
+
+// OnStateChange registers a callback run on every state transition. It is
+// called with the breaker lock held and must not call back into the breaker.
+func (s *Set) OnStateChange(fn func(name string, from, to State)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onChange = fn
+}
+
+// Get returns the breaker for name, creating it if needed.
+func (s *Set) Get(name string) *Breaker {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.breakers[name]
+	if !ok {
+		b = New(name, s.settings)
+		b.onChange = s.onChange
+		s.breakers[name] = b
+	}
+	return b
+}
+
+// Snapshots returns the state of every breaker, sorted by name.
+func (s *Set) Snapshots() []Snapshot {
+	s.mu.Lock()
+	breakers := make([]*Breaker, 0, len(s.breakers))
+	for _, b := range s.breakers {
+		breakers = append(breakers, b)
+	}
+	s.mu.Unlock()
+
+	snapshots := make([]Snapshot, 0, len(breakers))
+	for _, b := range breakers {
+		snapshots = append(snapshots, b.Snapshot())
+	}
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].Name < snapshots[j].Name })
+	return snapshots
+}
@@ -0,0 +1,67 @@
+package breaker
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSetGet(t *testing.T) {
+	s := NewSet(testSettings)
+	a := s.Get("a")
+	if s.Get("a") != a {
+		t.Error("Get returned a new breaker for a known name")
+	}
+	if s.Get("b") == a {
+		t.Error("Get returned the same breaker for different names")
+	}
+	if a.Name() != "a" || a.settings != testSettings {
+		t.Errorf("breaker = %q with %+v, want a with the set settings", a.Name(), a.settings)
+	}
+}
+
+func TestSetOnStateChange(t *testing.T) {
+	type transition struct {
+		name     string
+		from, to State
+	}
+	var got []transition
+	s := NewSet(testSettings)
+	s.OnStateChange(func(name string, from, to State) {
+		got = append(got, transition{name, from, to})
+	})
+
+	b := s.Get("a")
+	for i := 0; i < testSettings.MinRequests; i++ {
+		call(t, b, false)
+	}
+	b.settings.CoolDown = 0
+	call(t, b, true)
+	call(t, b, true)
+
+	want := []transition{{"a", Closed, Open}, {"a", Open, HalfOpen}, {"a", HalfOpen, Closed}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("transitions = %v, want %v", got, want)
+	}
+}
+
+func TestSetSnapshots(t *testing.T) {
+	s := NewSet(testSettings)
+	for _, name := range []string{"c", "a", "b"} {
+		s.Get(name)
+	}
+	for i := 0; i < testSettings.MinRequests; i++ {
+		call(t, s.Get("b"), false)
+	}
+
+	var names, states []string
+	for _, snap := range s.Snapshots() {
+		names = append(names, snap.Name)
+		states = append(states, snap.State)
+	}
+	if want := []string{"a", "b", "c"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("snapshot names = %v, want %v", names, want)
+	}
+	if want := []string{"closed", "open", "closed"}; !reflect.DeepEqual(states, want) {
+		t.Errorf("snapshot states = %v, want %v", states, want)
+	}
+}
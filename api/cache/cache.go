@@ -0,0 +1,198 @@
+// Package cache keeps rendered responses of read endpoints in memory.
+package cache
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// Entry is a cached response.
+type Entry struct {
+	Status  int
+	Header  http.Header
+	Body    []byte
+	ETag    string
+	Expires time.Time
+}
+
+// Cache is a size bounded, least recently used response cache. Entries
+// belong to a group, e.g. "categories" or "post:<id>", that is dropped as
+// a whole when the underlying data changes.
+type Cache struct {
+	maxEntries int
+	now        func() time.Time
+	flight     singleflight.Group
+
+	mu     sync.Mutex
+	ll     *list.List
+	items  map[string]*list.Element
+	groups map[string]map[string]struct{}
+	epoch  uint64
+}
+
+type item struct {
+	key   string
+	group string
+	entry *Entry
+}
+
+// New returns a Cache holding at most maxEntries responses.
+func New(maxEntries int) *Cache {
+	if maxEntries <= 0 {
+		maxEntries = 10000
+	}
+	return &Cache{
+		maxEntries: maxEntries,
+		now:        time.Now,
+		ll:         list.New(),
+		items:      map[string]*list.Element{},
+		groups:     map[string]map[string]struct{}{},
+	}
+}
+
+// Get returns the unexpired entry stored under key.
+func (c *Cache) Get(key string) (*Entry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	it := el.Value.(*item)
+	if !c.now().Before(it.entry.Expires) {
+		c.remove(el)
+		return nil, false
+	}
+	c.ll.MoveToFront(el)
+	return it.entry, true
+}
+
+// Epoch returns a counter that advances on every invalidation.
+func (c *Cache) Epoch() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.epoch
+}
+
+// Set stores e under key as part of group, evicting the least recently
+// used entry when the cache is full. epoch is the Epoch read before the
+// response was rendered; e is dropped if an invalidation happened since,
+// as it may hold data from before the write.
+func (c *Cache) Set(key, group string, epoch uint64, e *Entry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if epoch != c.epoch {
+		return
+	}
+	if el, ok := c.items[key]; ok {
+		c.remove(el)
+	}
+	c.items[key] = c.ll.PushFront(&item{key: key, group: group, entry: e})
+	if c.groups[group] == nil {
+		c.groups[group] = map[string]struct{}{}
+	}
+	c.groups[group][key] = struct{}{}
+
+	for c.ll.Len() > c.maxEntries {
+		c.remove(c.ll.Back())
+	}
+}
+
+// Invalidate drops every entry of group.
+func (c *Cache) Invalidate(group string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key := range c.groups[group] {
+		if el, ok := c.items[key]; ok {
+			c.remove(el)
+		}
+	}
+	delete(c.groups, group)
+	c.epoch++
+}
+
+// Do collapses concurrent calls for the same key into one call of fn.
+// shared reports whether the result was handed to more than one caller.
+func (c *Cache) Do(key string, fn func() (*Entry, error)) (e *Entry, err error, shared bool) {
+	v, err, shared := c.flight.Do(key, func() (interface{}, error) {
+		return fn()
+	})
+	e, _ = v.(*Entry)
+	return e, err, shared
+}
+
+// remove must be called with c.mu held.
+func (c *Cache) remove(el *list.Element) {
+	it := el.Value.(*item)
+	c.ll.Remove(el)
+	delete(c.items, it.key)
+	if keys := c.groups[it.group]; keys != nil {
+		delete(keys, it.key)
+		if len(keys) == 0 {
+			delete(c.groups, it.group)
+		}
+	}
+}
+
+// ETag returns a strong entity tag for body.
+func ETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + base64.RawURLEncoding.EncodeToString(sum[:16]) + `"`
+}
+
+// MatchesETag reports whether an If-None-Match header value matches etag.
+// Weak comparison is used, as RFC 9110 requires for If-None-Match.
+func MatchesETag(ifNoneMatch, etag string) bool {
+	for _, tag := range splitETags(ifNoneMatch) {
+		if tag == "*" || trimWeak(tag) == trimWeak(etag) {
+			return true
+		}
+	}
+	return false
+}
+
+func splitETags(header string) []string {
+	var (
+		tags  []string
+		start = -1
+		quote bool
+	)
+	for i, r := range header {
+		switch {
+		case r == '"':
+			quote = !quote
+			if start < 0 {
+				start = i
+			}
+		case (r == ',' || r == ' ' || r == '\t') && !quote:
+			if start >= 0 {
+				tags = append(tags, header[start:i])
+				start = -1
+			}
+		default:
+			if start < 0 {
+				start = i
+			}
+		}
+	}
+	if start >= 0 {
+		tags = append(tags, header[start:])
+	}
+	return tags
+}
+
+func trimWeak(tag string) string {
+	if len(tag) > 2 && tag[:2] == "W/" {
+		return tag[2:]
+	}
+	return tag
+}
@@ -0,0 +1,172 @@
+package cache
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var testNow = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// newTestCache returns a cache whose clock only moves through the returned
+// advance function.
+func newTestCache(maxEntries int) (*Cache, func(time.Duration)) {
+	c := New(maxEntries)
+	now := testNow
+	c.now = func() time.Time { return now }
+	return c, func(d time.Duration) { now = now.Add(d) }
+}
+
+func entry(body string) *Entry {
+	return &Entry{Status: 200, Body: []byte(body), Expires: testNow.Add(time.Minute)}
+}
+
+func TestCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c, _ := newTestCache(2)
+	c.Set("a", "g", c.Epoch(), entry("a"))
+	c.Set("b", "g", c.Epoch(), entry("b"))
+	if _, ok := c.Get("a"); !ok {
+		t.Fatal("a missing before eviction")
+	}
+	c.Set("c", "g", c.Epoch(), entry("c"))
+
+	for key, want := range map[string]bool{"a": true, "b": false, "c": true} {
+		if _, ok := c.Get(key); ok != want {
+			t.Errorf("Get(%q) found = %v, want %v", key, ok, want)
+		}
+	}
+	if n := c.ll.Len(); n != 2 {
+		t.Errorf("entries = %d, want 2", n)
+	}
+}
+
+func TestCacheExpiresEntries(t *testing.T) {
+	c, advance := newTestCache(10)
+	c.Set("a", "g", c.Epoch(), entry("a"))
+
+	advance(time.Minute - time.Nanosecond)
+	if _, ok := c.Get("a"); !ok {
+		t.Fatal("entry expired before its TTL")
+	}
+	advance(time.Nanosecond)
+	if _, ok := c.Get("a"); ok {
+		t.Fatal("entry served after its TTL")
+	}
+	if _, ok := c.groups["g"]; ok || c.ll.Len() != 0 {
+		t.Error("expired entry was not removed")
+	}
+}
+
+func TestCacheInvalidate(t *testing.T) {
+	c, _ := newTestCache(10)
+	c.Set("a1", "a", c.Epoch(), entry("a1"))
+	c.Set("a2", "a", c.Epoch(), entry("a2"))
+	c.Set("b1", "b", c.Epoch(), entry("b1"))
+
+	c.Invalidate("a")
+	for key, want := range map[string]bool{"a1": false, "a2": false, "b1": true} {
+		if _, ok := c.Get(key); ok != want {
+			t.Errorf("Get(%q) found = %v, want %v", key, ok, want)
+		}
+	}
+}
+
+func TestCacheDropsFillsRacingInvalidation(t *testing.T) {
+	c, _ := newTestCache(10)
+	filling := make(chan struct{})
+	invalidated := make(chan struct{})
+
+	go func() {
+		<-filling
+		c.Invalidate("post:1")
+		close(invalidated)
+	}()
+	c.Do("k", func() (*Entry, error) {
+		epoch := c.Epoch()
+		close(filling)
+		// The write lands while the old response is being rendered.
+		<-invalidated
+		e := entry("stale")
+		c.Set("k", "post:1", epoch, e)
+		return e, nil
+	})
+
+	if e, ok := c.Get("k"); ok {
+		t.Errorf("cached %q rendered before an invalidation", e.Body)
+	}
+
+	c.Set("k", "post:1", c.Epoch(), entry("fresh"))
+	if _, ok := c.Get("k"); !ok {
+		t.Error("fill after the invalidation was dropped")
+	}
+}
+
+func TestCacheDoCollapsesConcurrentCalls(t *testing.T) {
+	c, _ := newTestCache(10)
+	var calls atomic.Int32
+	release := make(chan struct{})
+
+	const n = 10
+	var (
+		wg      sync.WaitGroup
+		started sync.WaitGroup
+		shared  atomic.Int32
+	)
+	started.Add(n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			started.Done()
+			e, err, sh := c.Do("k", func() (*Entry, error) {
+				calls.Add(1)
+				<-release
+				return entry("v"), nil
+			})
+			if err != nil || string(e.Body) != "v" {
+				t.Errorf("Do = %v, %v", e, err)
+			}
+			if sh {
+				shared.Add(1)
+			}
+		}()
+	}
+	started.Wait()
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := calls.Load(); n != 1 {
+		t.Errorf("fn calls = %d, want 1", n)
+	}
+	if got := shared.Load(); got != n {
+		t.Errorf("shared results = %d, want %d", got, n)
+	}
+}
+
+func TestMatchesETag(t *testing.T) {
+	etag := ETag([]byte("body"))
+	tests := []struct {
+		header string
+		want   bool
+	}{
+		{"", false},
+		{etag, true},
+		{"W/" + etag, true},
+		{"*", true},
+		{`"other"`, false},
+		{fmt.Sprintf(`"other", %s`, etag), true},
+		{fmt.Sprintf(`"a,b",W/%s`, etag), true},
+		{`"a,b"`, false},
+	}
+	for _, tt := range tests {
+		if got := MatchesETag(tt.header, etag); got != tt.want {
+			t.Errorf("MatchesETag(%q) = %v, want %v", tt.header, got, tt.want)
+		}
+	}
+	if ETag([]byte("a")) == ETag([]byte("b")) {
+		t.Error("ETag is the same for different bodies")
+	}
+}
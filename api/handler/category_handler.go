@@ -5,6 +5,7 @@ import (
 
 	"github.com/rs/zerolog/log"
 
+	"github.com/Forum-service/Forum-api-gateway/api/response"
 	"github.com/Forum-service/Forum-api-gateway/genproto/category"
 	"github.com/gin-gonic/gin"
 )
@@ -17,25 +18,24 @@ import (
 // @Produce json
 // @Security BearerAuth
 // @Param category body category.CreateCategoryRequest true "Category information"
+// @Param Idempotency-Key header string false "Makes retries of the request safe, the first response is replayed"
 // @Success 201 {object} category.Category
-// @Failure 400 {object} string "Invalid request body"
-// @Failure 500 {object} string "Internal server error"
+// @Failure 400 {object} response.ErrorResponse "Invalid request body"
+// @Failure 409 {object} response.ErrorResponse "Idempotency-Key already used for a different request"
+// @Failure 425 {object} response.ErrorResponse "Request with the same Idempotency-Key still in progress"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
 // @Router /categories [post]
 func (h *Handler) CreateCategory(c *gin.Context) {
 	var req category.CreateCategoryRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		log.Error().Err(err).Msg("failed to bind json")
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": err.Error(),
-		})
+		log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to bind json")
+		response.AbortWithError(c, http.StatusBadRequest, err.Error())
 		return
 	}
 	resp, err := h.CategoryService.CreateCategory(c.Request.Context(), &req)
 	if err != nil {
-		log.Error().Err(err).Msg("failed to create category")
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": err.Error(),
-		})
+		log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to create category")
+		response.AbortWithGRPCError(c, err)
 		return
 	}
 	c.JSON(http.StatusCreated, resp)
@@ -49,25 +49,26 @@ func (h *Handler) CreateCategory(c *gin.Context) {
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "Category ID"
+// @Param If-None-Match header string false "ETag of a cached copy; 304 if it is still current"
 // @Success 200 {object} category.Category
-// @Failure 400 {object} string "Invalid request body"
-// @Failure 404 {object} string "Category item not found"
-// @Failure 500 {object} string "Internal server error"
+// @Header 200 {string} ETag "Version of the category, send it as If-Match to update it"
+// @Success 304 "Not modified"
+// @Failure 400 {object} response.ErrorResponse "Invalid request body"
+// @Failure 404 {object} response.ErrorResponse "Category item not found"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
 // @Router /categories/{id} [get]
 func (h *Handler) GetCategoryById(c *gin.Context) {
 	id := c.Param("id")
-	log.Print(id)
+	log.Ctx(c.Request.Context()).Debug().Str("id", id).Msg("get category")
 	resp, err := h.CategoryService.GetCategory(c.Request.Context(), &category.GetCategoryRequest{
 		Id: id,
 	})
 	if err != nil {
-		log.Error().Err(err).Msg("failed to get category")
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": err.Error(),
-		})
+		log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to get category")
+		response.AbortWithGRPCError(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, resp)
+	writeVersioned(c, resp, resp.GetCategory().GetUpdatedAt())
 }
 
 // UpdateCategory godoc
@@ -79,28 +80,72 @@ func (h *Handler) GetCategoryById(c *gin.Context) {
 // @Security BearerAuth
 // @Param id path string true "Category ID"
 // @Param category body category.UpdateCategoryRequest true "Category information"
+// @Param If-Match header string false "ETag of the version being updated; the update fails with 412 if the category changed since"
 // @Success 200 {object} category.Category
-// @Failure 400 {object} string "Invalid request body"
-// @Failure 404 {object} string "Category item not found"
-// @Failure 500 {object} string "Internal server error"
+// @Header 200 {string} ETag "New version of the category"
+// @Failure 400 {object} response.ErrorResponse "Invalid request body or If-Match header"
+// @Failure 404 {object} response.ErrorResponse "Category item not found"
+// @Failure 412 {object} response.ErrorResponse "The category was modified since the If-Match version"
+// @Failure 428 {object} response.ErrorResponse "If-Match is required"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
 // @Router /categories [put]
 func (h *Handler) UpdateCategory(c *gin.Context) {
 	var req category.UpdateCategoryRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		log.Error().Err(err).Msg("failed to bind json")
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": err.Error(),
-		})
+		log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to bind json")
+		response.AbortWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !h.bindIfMatch(c, &req.ExpectedUpdatedAt) {
+		return
+	}
+	resp, err := h.CategoryService.UpdateCategory(c.Request.Context(), &req)
+	if err != nil {
+		log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to update category")
+		abortWithUpdateError(c, err, req.ExpectedUpdatedAt)
+		return
+	}
+	setVersion(c, resp.GetCategory().GetUpdatedAt())
+	c.JSON(http.StatusOK, resp)
+}
+
+// PatchCategory godoc
+// @Summary Partially update a category
+// @Description Change only the fields present in a JSON Merge Patch (RFC 7396): name. A field set to null is cleared.
+// @Tags category
+// @Accept json,application/merge-patch+json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Category ID"
+// @Param patch body object true "Fields to change: name"
+// @Param If-Match header string false "ETag of the version being updated; the update fails with 412 if the category changed since"
+// @Success 200 {object} category.Category
+// @Header 200 {string} ETag "New version of the category"
+// @Failure 400 {object} response.ErrorResponse "Invalid patch or If-Match header"
+// @Failure 404 {object} response.ErrorResponse "Category item not found"
+// @Failure 412 {object} response.ErrorResponse "The category was modified since the If-Match version"
+// @Failure 415 {object} response.ErrorResponse "Unsupported Content-Type"
+// @Failure 428 {object} response.ErrorResponse "If-Match is required"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /categories/{id} [patch]
+func (h *Handler) PatchCategory(c *gin.Context) {
+	var req category.UpdateCategoryRequest
+	mask, ok := bindMergePatch(c, &req, categoryPatchFields)
+	if !ok {
+		return
+	}
+	req.Id = c.Param("id")
+	req.UpdateMask = mask
+	if !h.bindIfMatch(c, &req.ExpectedUpdatedAt) {
 		return
 	}
 	resp, err := h.CategoryService.UpdateCategory(c.Request.Context(), &req)
 	if err != nil {
-		log.Error().Err(err).Msg("failed to update category")
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": err.Error(),
-		})
+		log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to patch category")
+		abortWithUpdateError(c, err, req.ExpectedUpdatedAt)
 		return
 	}
+	setVersion(c, resp.GetCategory().GetUpdatedAt())
 	c.JSON(http.StatusOK, resp)
 }
 
@@ -113,17 +158,15 @@ func (h *Handler) UpdateCategory(c *gin.Context) {
 // @Security BearerAuth
 // @Param id path string true "Category ID"
 // @Success 200 {object} category.DeleteCategoryResponse
-// @Failure 404 {object} string "Category item not found"
-// @Failure 500 {object} string "Internal server error"
+// @Failure 404 {object} response.ErrorResponse "Category item not found"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
 // @Router /categories/{id} [delete]
 func (h *Handler) DeleteCategory(c *gin.Context) {
 	id := c.Param("id")
 	resp, err := h.CategoryService.DeleteCategory(c.Request.Context(), &category.DeleteCategoryRequest{Id: id})
 	if err != nil {
-		log.Error().Err(err).Msg("failed to delete category")
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": err.Error(),
-		})
+		log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to delete category")
+		response.AbortWithGRPCError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, resp)
@@ -131,48 +174,48 @@ func (h *Handler) DeleteCategory(c *gin.Context) {
 
 // GetAllCategories godoc
 // @Summary Get all categories
-// @Description Retrieve a list of all categories with optional pagination
+// @Description Retrieve a list of all categories with optional filtering, sorting and pagination
 // @Tags category
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param page query int false "Page number"
 // @Param limit query int false "Number of categories per page"
-// @Success 200 {object} category.GetAllCategoriesResponse
-// @Failure 500 {object} string "Internal server error"
+// @Param name query string false "Filter by name (partial match)"
+// @Param sort query string false "Comma separated sort fields, prefix with - for descending: name, created_at, updated_at"
+// @Param include_deleted query bool false "Include soft deleted items (admins only)"
+// @Success 200 {object} response.Page{data=[]category.Category}
+// @Failure 400 {object} response.ErrorResponse "Invalid query parameters"
+// @Failure 403 {object} response.ErrorResponse "include_deleted requires an admin"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
 // @Router /categories [get]
 func (h *Handler) GetAllCategories(c *gin.Context) {
 	var (
 		req category.GetAllCategoriesRequest
 		err error
 	)
-	req.Page, req.Limit, err = ReadPageLimit(c)
+	req.Name = c.Query("name")
+	req.Sort, err = readSort(c, categorySortFields)
 	if err != nil {
-		log.Error().Err(err).Msg("failed to read page limit")
-		c.JSON(http.StatusBadGateway, gin.H{
-			"error": err.Error(),
-		})
+		log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to read sort")
+		response.AbortWithError(c, http.StatusBadRequest, err.Error())
 		return
 	}
-	resp, err := h.CategoryService.GetAllCategories(c.Request.Context(), &req)
-	if err != nil {
-		log.Error().Err(err).Msg("failed to get categories")
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": err.Error(),
-		})
+	if !h.bindIncludeDeleted(c, &req.IncludeDeleted) {
 		return
 	}
 
-	resp1, err := h.CategoryService.GetCategory(c.Request.Context(), &category.GetCategoryRequest{
-		Id: resp.Categories[0].Id,
-	})
+	req.Page, req.Limit, err = h.ReadPageLimit(c)
 	if err != nil {
-		log.Error().Err(err).Msg("failed to get category")
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": err.Error(),
-		})
+		log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to read page limit")
+		response.AbortWithError(c, http.StatusBadRequest, err.Error())
 		return
 	}
-	log.Logger.Println(resp1)
-	c.JSON(http.StatusOK, resp)
+	resp, err := h.CategoryService.GetAllCategories(c.Request.Context(), &req)
+	if err != nil {
+		log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to get categories")
+		response.AbortWithGRPCError(c, err)
+		return
+	}
+	writePage(c, resp.GetCategories(), req.Page, req.Limit, resp.GetTotal())
 }
@@ -5,37 +5,41 @@ import (
 
 	"github.com/rs/zerolog/log"
 
+	"github.com/Forum-service/Forum-api-gateway/api/response"
 	"github.com/Forum-service/Forum-api-gateway/genproto/comment"
 	"github.com/gin-gonic/gin"
 )
 
 // CreateComment godoc
 // @Summary Create a new comment
-// @Description Create a new comment with given information
+// @Description Create a new comment with given information. The author is taken from the token; a user_id in the body must match it.
 // @Tags comment
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param comment body comment.CreateCommentRequest true "Comment information"
+// @Param Idempotency-Key header string false "Makes retries of the request safe, the first response is replayed"
 // @Success 201 {object} comment.Comment
-// @Failure 400 {object} string "Invalid request body"
-// @Failure 500 {object} string "Internal server error"
+// @Failure 400 {object} response.ErrorResponse "Invalid request body"
+// @Failure 403 {object} response.ErrorResponse "user_id does not match the token"
+// @Failure 409 {object} response.ErrorResponse "Idempotency-Key already used for a different request"
+// @Failure 425 {object} response.ErrorResponse "Request with the same Idempotency-Key still in progress"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
 // @Router /comments [post]
 func (h *Handler) CreateComment(c *gin.Context) {
 	var req comment.CreateCommentRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		log.Error().Err(err).Msg("failed to bind json")
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": err.Error(),
-		})
+		log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to bind json")
+		response.AbortWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !bindUserID(c, &req.UserId) {
 		return
 	}
 	resp, err := h.CommentService.CreateComment(c.Request.Context(), &req)
 	if err != nil {
-		log.Error().Err(err).Msg("failed to create comment")
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": err.Error(),
-		})
+		log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to create comment")
+		response.AbortWithGRPCError(c, err)
 		return
 	}
 	c.JSON(http.StatusCreated, resp)
@@ -49,10 +53,13 @@ func (h *Handler) CreateComment(c *gin.Context) {
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "Comment ID"
+// @Param If-None-Match header string false "ETag of a cached copy; 304 if it is still current"
 // @Success 200 {object} comment.Comment
-// @Failure 400 {object} string "Invalid request body"
-// @Failure 404 {object} string "Comment item not found"
-// @Failure 500 {object} string "Internal server error"
+// @Header 200 {string} ETag "Version of the comment, send it as If-Match to update it"
+// @Success 304 "Not modified"
+// @Failure 400 {object} response.ErrorResponse "Invalid request body"
+// @Failure 404 {object} response.ErrorResponse "Comment item not found"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
 // @Router /comments/{id} [get]
 func (h *Handler) GetCommentById(c *gin.Context) {
 	id := c.Param("id")
@@ -61,13 +68,11 @@ func (h *Handler) GetCommentById(c *gin.Context) {
 		Id: id,
 	})
 	if err != nil {
-		log.Error().Err(err).Msg("failed to get comment")
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": err.Error(),
-		})
+		log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to get comment")
+		response.AbortWithGRPCError(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, resp)
+	writeVersioned(c, resp, resp.GetComment().GetUpdatedAt())
 }
 
 // UpdateComment godoc
@@ -79,30 +84,77 @@ func (h *Handler) GetCommentById(c *gin.Context) {
 // @Security BearerAuth
 // @Param id path string true "Comment ID"
 // @Param comment body comment.UpdateCommentRequest true "Comment information"
+// @Param If-Match header string false "ETag of the version being updated; the update fails with 412 if the comment changed since"
 // @Success 200 {object} comment.Comment
-// @Failure 400 {object} string "Invalid request body"
-// @Failure 404 {object} string "Comment item not found"
-// @Failure 500 {object} string "Internal server error"
+// @Header 200 {string} ETag "New version of the comment"
+// @Failure 400 {object} response.ErrorResponse "Invalid request body or If-Match header"
+// @Failure 403 {object} response.ErrorResponse "Caller is neither the author nor a moderator"
+// @Failure 404 {object} response.ErrorResponse "Comment item not found"
+// @Failure 412 {object} response.ErrorResponse "The comment was modified since the If-Match version"
+// @Failure 428 {object} response.ErrorResponse "If-Match is required"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
 // @Router /comments/{id} [put]
 func (h *Handler) UpdateComment(c *gin.Context) {
 	var req comment.UpdateCommentRequest
-	id := c.Param("id")
-	req.Id = id
 	if err := c.ShouldBindJSON(&req); err != nil {
-		log.Error().Err(err).Msg("failed to bind json")
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": err.Error(),
-		})
+		log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to bind json")
+		response.AbortWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	// Set after binding so an id in the body cannot redirect the update
+	// away from the resource authorized by path.
+	req.Id = c.Param("id")
+	if !h.bindIfMatch(c, &req.ExpectedUpdatedAt) {
 		return
 	}
 	resp, err := h.CommentService.UpdateComment(c.Request.Context(), &req)
 	if err != nil {
-		log.Error().Err(err).Msg("failed to update comment")
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": err.Error(),
-		})
+		log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to update comment")
+		abortWithUpdateError(c, err, req.ExpectedUpdatedAt)
+		return
+	}
+	setVersion(c, resp.GetComment().GetUpdatedAt())
+	c.JSON(http.StatusOK, resp)
+}
+
+// PatchComment godoc
+// @Summary Partially update a comment
+// @Description Change only the fields present in a JSON Merge Patch (RFC 7396): body. A field set to null is cleared.
+// @Tags comment
+// @Accept json,application/merge-patch+json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Comment ID"
+// @Param patch body object true "Fields to change: body"
+// @Param If-Match header string false "ETag of the version being updated; the update fails with 412 if the comment changed since"
+// @Success 200 {object} comment.Comment
+// @Header 200 {string} ETag "New version of the comment"
+// @Failure 400 {object} response.ErrorResponse "Invalid patch or If-Match header"
+// @Failure 403 {object} response.ErrorResponse "Caller is neither the author nor a moderator"
+// @Failure 404 {object} response.ErrorResponse "Comment item not found"
+// @Failure 412 {object} response.ErrorResponse "The comment was modified since the If-Match version"
+// @Failure 415 {object} response.ErrorResponse "Unsupported Content-Type"
+// @Failure 428 {object} response.ErrorResponse "If-Match is required"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /comments/{id} [patch]
+func (h *Handler) PatchComment(c *gin.Context) {
+	var req comment.UpdateCommentRequest
+	mask, ok := bindMergePatch(c, &req, commentPatchFields)
+	if !ok {
+		return
+	}
+	req.Id = c.Param("id")
+	req.UpdateMask = mask
+	if !h.bindIfMatch(c, &req.ExpectedUpdatedAt) {
+		return
+	}
+	resp, err := h.CommentService.UpdateComment(c.Request.Context(), &req)
+	if err != nil {
+		log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to patch comment")
+		abortWithUpdateError(c, err, req.ExpectedUpdatedAt)
 		return
 	}
+	setVersion(c, resp.GetComment().GetUpdatedAt())
 	c.JSON(http.StatusOK, resp)
 }
 
@@ -115,18 +167,17 @@ func (h *Handler) UpdateComment(c *gin.Context) {
 // @Security BearerAuth
 // @Param id path string true "Comment ID"
 // @Success 200 {object} comment.DeleteCommentResponse
-// @Failure 400 {object} string "Invalid request body"
-// @Failure 404 {object} string "Comment item not found"
-// @Failure 500 {object} string "Internal server error"
+// @Failure 400 {object} response.ErrorResponse "Invalid request body"
+// @Failure 403 {object} response.ErrorResponse "Caller is neither the author nor a moderator"
+// @Failure 404 {object} response.ErrorResponse "Comment item not found"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
 // @Router /comments/{id} [delete]
 func (h *Handler) DeleteComment(c *gin.Context) {
 	id := c.Param("id")
 	resp, err := h.CommentService.DeleteComment(c.Request.Context(), &comment.DeleteCommentRequest{Id: id})
 	if err != nil {
-		log.Error().Err(err).Msg("failed to delete comment")
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": err.Error(),
-		})
+		log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to delete comment")
+		response.AbortWithGRPCError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, resp)
@@ -134,17 +185,25 @@ func (h *Handler) DeleteComment(c *gin.Context) {
 
 // GetAllComments godoc
 // @Summary Get all comments
-// @Description Retrieve a list of all comments with optional filtering and pagination
+// @Description Retrieve a list of all comments with optional filtering and pagination. With the cursor parameter the newest comments come first and the response is a response.CursorPage carrying next_cursor.
 // @Tags comment
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param page query int false "Page number"
 // @Param limit query int false "Number of comments per page"
+// @Param cursor query string false "Opaque cursor from pagination.next_cursor; switches to keyset pagination, pass it empty for the first page"
 // @Param post_id query string false "Filter by post ID"
 // @Param user_id query string false "Filter by user ID"
-// @Success 200 {object} comment.GetAllCommentsResponse
-// @Failure 500 {object} string "Internal server error"
+// @Param parent_id query string false "Only replies to this comment"
+// @Param sort query string false "Comma separated sort fields, prefix with - for descending: created_at, updated_at"
+// @Param created_after query string false "Only items created at or after this RFC 3339 time"
+// @Param created_before query string false "Only items created before this RFC 3339 time"
+// @Param include_deleted query bool false "Include soft deleted items (admins only)"
+// @Success 200 {object} response.Page{data=[]comment.Comment}
+// @Failure 400 {object} response.ErrorResponse "Invalid query parameters"
+// @Failure 403 {object} response.ErrorResponse "include_deleted requires an admin"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
 // @Router /comments [get]
 func (h *Handler) GetAllComments(c *gin.Context) {
 	var (
@@ -153,22 +212,206 @@ func (h *Handler) GetAllComments(c *gin.Context) {
 	)
 	req.PostId = c.Query("post_id")
 	req.UserId = c.Query("user_id")
+	if parentID := c.Query("parent_id"); parentID != "" {
+		req.ParentIds = []string{parentID}
+	}
+	req.Sort, err = readSort(c, commentSortFields)
+	if err != nil {
+		log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to read sort")
+		response.AbortWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	req.CreatedAfter, req.CreatedBefore, err = readCreatedRange(c)
+	if err != nil {
+		log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to read created range")
+		response.AbortWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !h.bindIncludeDeleted(c, &req.IncludeDeleted) {
+		return
+	}
 
-	req.Page, req.Limit, err = ReadPageLimit(c)
+	cursorMode := usesCursor(c)
+	if cursorMode {
+		var after cursor
+		after, req.Limit, err = h.ReadCursor(c, "comments")
+		if err != nil {
+			log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to read cursor")
+			response.AbortWithError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		req.AfterCreatedAt, req.AfterId = after.CreatedAt, after.ID
+		req.Sort = cursorSort
+		req.Page = 1
+		req.Limit++
+	} else {
+		req.Page, req.Limit, err = h.ReadPageLimit(c)
+		if err != nil {
+			log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to read page limit")
+			response.AbortWithError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	resp, err := h.CommentService.GetAllComments(c.Request.Context(), &req)
 	if err != nil {
-		log.Error().Err(err).Msg("failed to read page limit")
-		c.JSON(http.StatusBadGateway, gin.H{
-			"error": err.Error(),
+		log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to get comments")
+		response.AbortWithGRPCError(c, err)
+		return
+	}
+	if cursorMode {
+		writeCursorPage(c, h.cursors, "comments", resp.GetComments(), req.Limit-1, func(v *comment.Comment) (string, string) {
+			return v.GetCreatedAt(), v.GetId()
 		})
 		return
 	}
+	writePage(c, resp.GetComments(), req.Page, req.Limit, resp.GetTotal())
+}
+
+// GetCommentReplies godoc
+// @Summary Get replies to a comment
+// @Description Retrieve the direct replies to a comment, oldest first unless sorted otherwise
+// @Tags comment
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Comment ID"
+// @Param page query int false "Page number"
+// @Param limit query int false "Number of replies per page"
+// @Param sort query string false "Comma separated sort fields, prefix with - for descending: created_at, updated_at"
+// @Success 200 {object} response.Page{data=[]comment.Comment}
+// @Failure 400 {object} response.ErrorResponse "Invalid query parameters"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /comments/{id}/replies [get]
+func (h *Handler) GetCommentReplies(c *gin.Context) {
+	var (
+		req comment.GetAllCommentsRequest
+		err error
+	)
+	req.ParentIds = []string{c.Param("id")}
+	req.Sort, err = readSort(c, commentSortFields)
+	if err != nil {
+		log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to read sort")
+		response.AbortWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if req.Sort == nil {
+		req.Sort = []string{"created_at"}
+	}
+
+	req.Page, req.Limit, err = h.ReadPageLimit(c)
+	if err != nil {
+		log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to read page limit")
+		response.AbortWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
 	resp, err := h.CommentService.GetAllComments(c.Request.Context(), &req)
 	if err != nil {
-		log.Error().Err(err).Msg("failed to get comments")
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": err.Error(),
-		})
+		log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to get replies")
+		response.AbortWithGRPCError(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, resp)
+	writePage(c, resp.GetComments(), req.Page, req.Limit, resp.GetTotal())
+}
+
+// GetPostComments godoc
+// @Summary Get the comments of a post
+// @Description Retrieve the comments of a post as a flat list
+// @Tags comment
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Post ID"
+// @Param page query int false "Page number"
+// @Param limit query int false "Number of comments per page"
+// @Param sort query string false "Comma separated sort fields, prefix with - for descending: created_at, updated_at"
+// @Success 200 {object} response.Page{data=[]comment.Comment}
+// @Failure 400 {object} response.ErrorResponse "Invalid query parameters"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /posts/{id}/comments [get]
+func (h *Handler) GetPostComments(c *gin.Context) {
+	h.listPostComments(c, false)
+}
+
+// GetPostCommentTree godoc
+// @Summary Get the comment tree of a post
+// @Description Retrieve the top level comments of a post with their replies nested up to the given depth
+// @Tags comment
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Post ID"
+// @Param depth query int false "Reply levels to load"
+// @Param page query int false "Page number"
+// @Param limit query int false "Number of top level comments per page"
+// @Param sort query string false "Comma separated sort fields, prefix with - for descending: created_at, updated_at"
+// @Success 200 {object} response.Page{data=[]handler.CommentNode}
+// @Failure 400 {object} response.ErrorResponse "Invalid query parameters"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /posts/{id}/comments/tree [get]
+func (h *Handler) GetPostCommentTree(c *gin.Context) {
+	h.listPostComments(c, true)
+}
+
+// listPostComments writes a page of the comments of the post addressed by
+// the id path parameter. A tree page holds top level comments with their
+// replies nested.
+func (h *Handler) listPostComments(c *gin.Context, tree bool) {
+	var (
+		req   comment.GetAllCommentsRequest
+		depth int
+		err   error
+	)
+	req.PostId = c.Param("id")
+	req.TopLevelOnly = tree
+	if tree {
+		depth, err = h.readDepth(c)
+		if err != nil {
+			log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to read depth")
+			response.AbortWithError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	req.Sort, err = readSort(c, commentSortFields)
+	if err != nil {
+		log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to read sort")
+		response.AbortWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	req.Page, req.Limit, err = h.ReadPageLimit(c)
+	if err != nil {
+		log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to read page limit")
+		response.AbortWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := h.CommentService.GetAllComments(c.Request.Context(), &req)
+	if err != nil {
+		log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to get post comments")
+		response.AbortWithGRPCError(c, err)
+		return
+	}
+	if !tree {
+		writePage(c, resp.GetComments(), req.Page, req.Limit, resp.GetTotal())
+		return
+	}
+
+	nodes, err := h.commentTree(c.Request.Context(), req.PostId, resp.GetComments(), depth)
+	if err != nil {
+		log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to load replies")
+		response.AbortWithGRPCError(c, err)
+		return
+	}
+	writePage(c, nodes, req.Page, req.Limit, resp.GetTotal())
+}
+
+// CommentOwner returns the author of the comment addressed by the id path parameter.
+// It is used by the authorization policy for ownership checks.
+func (h *Handler) CommentOwner(c *gin.Context) (string, error) {
+	resp, err := h.CommentService.GetComment(c.Request.Context(), &comment.GetCommentRequest{
+		Id: c.Param("id"),
+	})
+	if err != nil {
+		return "", err
+	}
+	return resp.GetComment().GetUserId(), nil
 }
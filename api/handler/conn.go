@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/Forum-service/Forum-api-gateway/config"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/keepalive"
+)
+
+// dial creates a client connection to an upstream service.
+func dial(cfg config.GRPCClientConfig, interceptors ...grpc.UnaryClientInterceptor) (*grpc.ClientConn, error) {
+	creds := insecure.NewCredentials()
+	if cfg.TLS {
+		tlsCfg, err := clientTLSConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		creds = credentials.NewTLS(tlsCfg)
+	}
+
+	opts := []grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
+		grpc.WithDefaultCallOptions(
+			grpc.MaxCallRecvMsgSize(cfg.MaxRecvMsgSize),
+			grpc.MaxCallSendMsgSize(cfg.MaxSendMsgSize),
+		),
+		grpc.WithChainUnaryInterceptor(interceptors...),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+	}
+	if cfg.KeepaliveTime > 0 {
+		opts = append(opts, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                cfg.KeepaliveTime,
+			Timeout:             cfg.KeepaliveTimeout,
+			PermitWithoutStream: cfg.KeepalivePermitWithoutStream,
+		}))
+	}
+
+	return grpc.NewClient(cfg.Address, opts...)
+}
+
+// clientTLSConfig builds TLS settings from the CA, certificate and key
+// files. A certificate and key pair enables mutual TLS.
+func clientTLSConfig(cfg config.GRPCClientConfig) (*tls.Config, error) {
+	tlsCfg := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: cfg.TLSServerName,
+	}
+
+	if cfg.TLSCAFile != "" {
+		pem, err := os.ReadFile(cfg.TLSCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading CA file: %v", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New("no certificates found in CA file")
+		}
+		tlsCfg.RootCAs = pool
+	}
+
+	if cfg.TLSCertFile != "" || cfg.TLSKeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.TLSCertFile, cfg.TLSKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("error loading client certificate: %v", err)
+		}
+		tlsCfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsCfg, nil
+}
@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Forum-service/Forum-api-gateway/api/response"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+var errInvalidCursor = errors.New("invalid cursor")
+
+// cursorSort is the order keyset pages are read in. It is sent with every
+// cursor request, the first page included, so all pages share one order.
+var cursorSort = []string{"-created_at", "-id"}
+
+// cursor is the position of the last item of a keyset page. Scope ties the
+// cursor to the listing and filters it was issued for.
+type cursor struct {
+	Scope     string `json:"s"`
+	CreatedAt string `json:"t"`
+	ID        string `json:"i"`
+}
+
+// cursorCodec turns cursors into opaque, HMAC signed tokens so clients
+// cannot forge positions or reuse a cursor with different filters.
+type cursorCodec struct {
+	key []byte
+}
+
+func newCursorCodec(secret string) cursorCodec {
+	if secret != "" {
+		return cursorCodec{key: []byte(secret)}
+	}
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		panic(err)
+	}
+	log.Warn().Msg("CURSOR_SECRET is not set, cursors will not survive a restart or work across replicas")
+	return cursorCodec{key: key}
+}
+
+func (cc cursorCodec) encode(cur cursor) string {
+	payload, _ := json.Marshal(cur)
+	return base64.RawURLEncoding.EncodeToString(payload) + "." +
+		base64.RawURLEncoding.EncodeToString(cc.sign(payload))
+}
+
+func (cc cursorCodec) decode(token string) (cursor, error) {
+	p, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return cursor{}, errInvalidCursor
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(p)
+	if err != nil {
+		return cursor{}, errInvalidCursor
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(mac, cc.sign(payload)) {
+		return cursor{}, errInvalidCursor
+	}
+	var cur cursor
+	if err := json.Unmarshal(payload, &cur); err != nil {
+		return cursor{}, errInvalidCursor
+	}
+	return cur, nil
+}
+
+func (cc cursorCodec) sign(payload []byte) []byte {
+	m := hmac.New(sha256.New, cc.key)
+	m.Write(payload)
+	return m.Sum(nil)
+}
+
+// cursorScope identifies a listing together with its filters, i.e. every
+// query parameter except the pagination ones.
+func cursorScope(c *gin.Context, listing string) string {
+	q := c.Request.URL.Query()
+	q.Del("cursor")
+	q.Del("limit")
+	sum := sha256.Sum256([]byte(listing + "?" + q.Encode()))
+	return base64.RawURLEncoding.EncodeToString(sum[:12])
+}
+
+// usesCursor reports whether the request asks for keyset pagination. An
+// empty cursor parameter requests the first page.
+func usesCursor(c *gin.Context) bool {
+	_, ok := c.GetQuery("cursor")
+	return ok
+}
+
+// ReadCursor reads the cursor and limit query parameters of a keyset
+// paginated listing. The zero cursor is returned for the first page.
+func (h *Handler) ReadCursor(c *gin.Context, listing string) (cursor, int32, error) {
+	if c.Query("page") != "" {
+		return cursor{}, 0, errors.New("page and cursor cannot be combined")
+	}
+	if c.Query("sort") != "" {
+		return cursor{}, 0, errors.New("sort and cursor cannot be combined, cursor pages are ordered by created_at descending")
+	}
+	limit, err := h.readLimit(c)
+	if err != nil {
+		return cursor{}, 0, err
+	}
+	token := c.Query("cursor")
+	if token == "" {
+		return cursor{}, limit, nil
+	}
+	cur, err := h.cursors.decode(token)
+	if err != nil {
+		return cursor{}, 0, err
+	}
+	if cur.Scope != cursorScope(c, listing) {
+		return cursor{}, 0, fmt.Errorf("%w: issued for a different listing or filters", errInvalidCursor)
+	}
+	return cur, limit, nil
+}
+
+// writeCursorPage responds with up to limit items wrapped in a
+// response.CursorPage. The upstream is asked for one extra item, whose
+// presence tells whether a next page exists. position returns the
+// created_at and id of an item.
+func writeCursorPage[T any](c *gin.Context, cc cursorCodec, listing string, items []T, limit int32, position func(T) (string, string)) {
+	if items == nil {
+		items = []T{}
+	}
+	hasNext := len(items) > int(limit)
+	if hasNext {
+		items = items[:limit]
+	}
+
+	page := response.CursorPagination{Limit: limit, HasNext: hasNext}
+	if hasNext {
+		createdAt, id := position(items[len(items)-1])
+		page.NextCursor = cc.encode(cursor{
+			Scope:     cursorScope(c, listing),
+			CreatedAt: createdAt,
+			ID:        id,
+		})
+
+		u := *c.Request.URL
+		q := u.Query()
+		q.Set("cursor", page.NextCursor)
+		q.Set("limit", fmt.Sprint(limit))
+		u.RawQuery = q.Encode()
+		c.Header("Link", fmt.Sprintf("<%s>; rel=%q", u.RequestURI(), "next"))
+	}
+
+	c.JSON(http.StatusOK, response.CursorPage{Data: items, Pagination: page})
+}
@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/Forum-service/Forum-api-gateway/genproto/comment"
+	"github.com/Forum-service/Forum-api-gateway/genproto/post"
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+)
+
+// postLister records the GetAllPosts requests it receives.
+type postLister struct {
+	post.PostServiceClient
+	reqs []*post.GetAllPostsRequest
+}
+
+func (p *postLister) GetAllPosts(_ context.Context, req *post.GetAllPostsRequest, _ ...grpc.CallOption) (*post.GetAllPostsResponse, error) {
+	p.reqs = append(p.reqs, req)
+	var resp post.GetAllPostsResponse
+	for i := int32(0); i < req.Limit; i++ {
+		resp.Posts = append(resp.Posts, &post.Post{Id: fmt.Sprint(i), CreatedAt: "2024-01-01T00:00:00Z"})
+	}
+	return &resp, nil
+}
+
+// commentLister records the GetAllComments requests it receives.
+type commentLister struct {
+	comment.CommentServiceClient
+	reqs []*comment.GetAllCommentsRequest
+}
+
+func (l *commentLister) GetAllComments(_ context.Context, req *comment.GetAllCommentsRequest, _ ...grpc.CallOption) (*comment.GetAllCommentsResponse, error) {
+	l.reqs = append(l.reqs, req)
+	var resp comment.GetAllCommentsResponse
+	for i := int32(0); i < req.Limit; i++ {
+		resp.Comments = append(resp.Comments, &comment.Comment{Id: fmt.Sprint(i), CreatedAt: "2024-01-01T00:00:00Z"})
+	}
+	return &resp, nil
+}
+
+func TestCursorRequestsSendKeysetSort(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	posts, comments := &postLister{}, &commentLister{}
+	h := &Handler{
+		PostService:    posts,
+		CommentService: comments,
+		pagination:     newPagination(10, 100),
+		cursors:        newCursorCodec("secret"),
+	}
+	r := gin.New()
+	r.GET("/posts", h.GetAllPosts)
+	r.GET("/categories/:id/posts", h.GetCategoryPosts)
+	r.GET("/comments", h.GetAllComments)
+
+	sorts := func() [][]string {
+		var s [][]string
+		for _, req := range posts.reqs {
+			s = append(s, req.Sort)
+		}
+		for _, req := range comments.reqs {
+			s = append(s, req.Sort)
+		}
+		posts.reqs, comments.reqs = nil, nil
+		return s
+	}
+
+	for _, path := range []string{"/posts", "/categories/c1/posts", "/comments"} {
+		first := get(t, r, path+"?cursor=&limit=2")
+		var page struct {
+			Pagination struct {
+				NextCursor string `json:"next_cursor"`
+			} `json:"pagination"`
+		}
+		if err := json.Unmarshal(first.Body.Bytes(), &page); err != nil || page.Pagination.NextCursor == "" {
+			t.Fatalf("%s first page: status %d, body %s", path, first.Code, first.Body)
+		}
+		if next := get(t, r, path+"?limit=2&cursor="+page.Pagination.NextCursor); next.Code != http.StatusOK {
+			t.Fatalf("%s next page: status %d, body %s", path, next.Code, next.Body)
+		}
+
+		got := sorts()
+		want := [][]string{cursorSort, cursorSort}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: upstream sort = %v, want %v for both pages", path, got, want)
+		}
+	}
+
+	if w := get(t, r, "/posts?page=1"); w.Code != http.StatusOK {
+		t.Fatalf("offset page: status %d", w.Code)
+	}
+	if got := sorts(); len(got) != 1 || got[0] != nil {
+		t.Errorf("offset page without sort: upstream sort = %v, want none", got)
+	}
+}
+
+func get(t *testing.T, r http.Handler, target string) *httptest.ResponseRecorder {
+	t.Helper()
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+	return w
+}
@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Forum-service/Forum-api-gateway/api/middlewares"
+	"github.com/Forum-service/Forum-api-gateway/api/response"
+	"github.com/gin-gonic/gin"
+)
+
+// Fields each listing may be sorted by.
+var (
+	postSortFields     = []string{"created_at", "updated_at", "title"}
+	commentSortFields  = []string{"created_at", "updated_at"}
+	categorySortFields = []string{"name", "created_at", "updated_at"}
+	tagSortFields      = []string{"name", "created_at", "updated_at"}
+)
+
+// readSort parses the sort query parameter, a comma separated list of
+// fields where a leading "-" sorts descending, e.g. -created_at,title.
+func readSort(c *gin.Context, allowed []string) ([]string, error) {
+	s := c.Query("sort")
+	if s == "" {
+		return nil, nil
+	}
+
+	var (
+		sort []string
+		seen = map[string]bool{}
+	)
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		field := strings.TrimPrefix(item, "-")
+		if !contains(allowed, field) {
+			return nil, fmt.Errorf("invalid sort field %q: must be one of %s", field, strings.Join(allowed, ", "))
+		}
+		if seen[field] {
+			return nil, fmt.Errorf("invalid sort: field %q given twice", field)
+		}
+		seen[field] = true
+		sort = append(sort, item)
+	}
+	return sort, nil
+}
+
+// readCreatedRange reads the created_after and created_before query
+// parameters as RFC 3339 timestamps and returns them normalized to UTC.
+func readCreatedRange(c *gin.Context) (string, string, error) {
+	after, err := readTime(c, "created_after")
+	if err != nil {
+		return "", "", err
+	}
+	before, err := readTime(c, "created_before")
+	if err != nil {
+		return "", "", err
+	}
+	if !after.IsZero() && !before.IsZero() && !after.Before(before) {
+		return "", "", fmt.Errorf("created_after must be before created_before")
+	}
+	return formatTime(after), formatTime(before), nil
+}
+
+func readTime(c *gin.Context, name string) (time.Time, error) {
+	s := c.Query(name)
+	if s == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s %q: must be an RFC 3339 timestamp", name, s)
+	}
+	return t, nil
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// bindIncludeDeleted sets *includeDeleted from the include_deleted query
+// parameter, which only callers holding one of the configured roles may
+// use. It aborts the request and returns false on failure.
+func (h *Handler) bindIncludeDeleted(c *gin.Context, includeDeleted *bool) bool {
+	s := c.Query("include_deleted")
+	if s == "" {
+		return true
+	}
+	v, err := strconv.ParseBool(s)
+	if err != nil {
+		response.AbortWithError(c, http.StatusBadRequest, "Invalid include_deleted parameter")
+		return false
+	}
+	if v {
+		principal, ok := middlewares.GetPrincipal(c)
+		if !ok || !principal.HasAny(h.includeDeletedRoles) {
+			response.AbortWithError(c, http.StatusForbidden, "Permission denied: include_deleted requires one of "+strings.Join(h.includeDeletedRoles, ", "))
+			return false
+		}
+	}
+	*includeDeleted = v
+	return true
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
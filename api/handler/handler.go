@@ -1,17 +1,26 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
-	"strconv"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/Forum-service/Forum-api-gateway/api/breaker"
+	"github.com/Forum-service/Forum-api-gateway/api/metrics"
+	"github.com/Forum-service/Forum-api-gateway/api/middlewares"
+	"github.com/Forum-service/Forum-api-gateway/api/response"
+	"github.com/Forum-service/Forum-api-gateway/config"
 
 	"github.com/Forum-service/Forum-api-gateway/genproto/category"
 	"github.com/Forum-service/Forum-api-gateway/genproto/comment"
 	"github.com/Forum-service/Forum-api-gateway/genproto/post"
 	"github.com/Forum-service/Forum-api-gateway/genproto/posttag"
+	"github.com/Forum-service/Forum-api-gateway/genproto/search"
 	"github.com/Forum-service/Forum-api-gateway/genproto/tag"
 	"github.com/gin-gonic/gin"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 )
 
 // Handler struct holds gRPC client connections.
@@ -21,55 +30,141 @@ type Handler struct {
 	PostService     post.PostServiceClient
 	CommentService  comment.CommentServiceClient
 	PostTagService  posttag.PostTagServiceClient
+	SearchService   search.SearchServiceClient
+
+	// Breakers holds the circuit breaker of each upstream service.
+	Breakers *breaker.Set
+
+	conns      []*grpc.ClientConn
+	upstreams  []upstream
+	health     healthConfig
+	pagination pagination
+	cursors    cursorCodec
+	threads    threadConfig
+	startedAt  time.Time
+
+	includeDeletedRoles []string
+	requireIfMatch      bool
+	draining            atomic.Bool
+}
+
+// upstream is the connection used for one gRPC service.
+type upstream struct {
+	service string
+	target  string
+	conn    *grpc.ClientConn
 }
 
 // NewHandler establishes gRPC connections and returns a Handler struct.
-func NewHandler() (*Handler, error) {
-	conn, err := grpc.NewClient(
-		"forum_service:8082",
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-	)
-	if err != nil {
-		return nil, fmt.Errorf("error connecting to gRPC server: %v", err)
-	}
+// Services configured with identical settings share one connection.
+func NewHandler(cfg config.Config) (*Handler, error) {
+	h := &Handler{
+		Breakers: breaker.NewSet(breaker.Settings{
+			FailureRatio:        cfg.Breaker.FailureRatio,
+			MinRequests:         cfg.Breaker.MinRequests,
+			Interval:            cfg.Breaker.Interval,
+			CoolDown:            cfg.Breaker.CoolDown,
+			HalfOpenMaxRequests: cfg.Breaker.HalfOpenMaxRequests,
+		}),
+		health: healthConfig{
+			checkUpstream: cfg.HealthCheckUpstream,
+			timeout:       cfg.ReadinessTimeout,
+		},
+		pagination: newPagination(cfg.DefaultLimit, cfg.MaxLimit),
+		cursors:    newCursorCodec(cfg.CursorSecret),
+		threads:    newThreadConfig(cfg.CommentTreeDepth, cfg.CommentTreeMaxDepth),
+		startedAt:  time.Now(),
 
-	return &Handler{
-		CategoryService: category.NewCategoryServiceClient(conn),
-		TagService:      tag.NewTagServiceClient(conn),
-		PostService:     post.NewPostServiceClient(conn),
-		CommentService:  comment.NewCommentServiceClient(conn),
-		PostTagService:  posttag.NewPostTagServiceClient(conn),
-	}, nil
-}
+		includeDeletedRoles: cfg.IncludeDeletedRoles,
+		requireIfMatch:      cfg.RequireIfMatch,
+	}
+	h.Breakers.OnStateChange(func(name string, _, to breaker.State) {
+		metrics.BreakerState.WithLabelValues(name).Set(float64(to))
+		metrics.BreakerTransitions.WithLabelValues(name, to.String()).Inc()
+	})
+	conns := map[config.GRPCClientConfig]*grpc.ClientConn{}
+	connect := func(c config.GRPCClientConfig) (*grpc.ClientConn, error) {
+		if conn, ok := conns[c]; ok {
+			return conn, nil
+		}
+		// The breaker sits inside the retry loop so that every attempt
+		// asks it for permission and reports its own outcome.
+		interceptors := []grpc.UnaryClientInterceptor{
+			metricsInterceptor(),
+			timeoutInterceptor(c.Timeout, cfg.GRPCMethodTimeouts),
+			retryInterceptor(cfg.GRPCRetry),
+		}
+		if cfg.Breaker.Enabled {
+			interceptors = append(interceptors, breakerInterceptor(h.Breakers))
+		}
 
-func ReadPageLimit(c *gin.Context) (int32, int32, error) {
-	var (
-		page  int64
-		limit int64
-		err   error
-	)
-
-	// Read page query parameter
-	pageStr := c.Query("page")
-	if pageStr != "" {
-		page, err = strconv.ParseInt(pageStr, 10, 32)
+		conn, err := dial(c, interceptors...)
 		if err != nil {
-			return 0, 0, err
+			return nil, fmt.Errorf("error connecting to gRPC server %s: %v", c.Address, err)
 		}
-	} else {
-		page = 1 // Default page
+		conns[c] = conn
+		h.conns = append(h.conns, conn)
+		return conn, nil
 	}
 
-	// Read limit query parameter
-	limitStr := c.Query("limit")
-	if limitStr != "" {
-		limit, err = strconv.ParseInt(limitStr, 10, 32)
+	for _, svc := range []struct {
+		name string
+		cfg  config.GRPCClientConfig
+		bind func(*grpc.ClientConn)
+	}{
+		{category.CategoryService_ServiceDesc.ServiceName, cfg.CategoryService, func(conn *grpc.ClientConn) { h.CategoryService = category.NewCategoryServiceClient(conn) }},
+		{tag.TagService_ServiceDesc.ServiceName, cfg.TagService, func(conn *grpc.ClientConn) { h.TagService = tag.NewTagServiceClient(conn) }},
+		{post.PostService_ServiceDesc.ServiceName, cfg.PostService, func(conn *grpc.ClientConn) { h.PostService = post.NewPostServiceClient(conn) }},
+		{comment.CommentService_ServiceDesc.ServiceName, cfg.CommentService, func(conn *grpc.ClientConn) { h.CommentService = comment.NewCommentServiceClient(conn) }},
+		{posttag.PostTagService_ServiceDesc.ServiceName, cfg.PostTagService, func(conn *grpc.ClientConn) { h.PostTagService = posttag.NewPostTagServiceClient(conn) }},
+		{search.SearchService_ServiceDesc.ServiceName, cfg.SearchService, func(conn *grpc.ClientConn) { h.SearchService = search.NewSearchServiceClient(conn) }},
+	} {
+		conn, err := connect(svc.cfg)
 		if err != nil {
-			return 0, 0, err
+			h.Close()
+			return nil, err
+		}
+		svc.bind(conn)
+		h.upstreams = append(h.upstreams, upstream{service: svc.name, target: svc.cfg.Address, conn: conn})
+		if cfg.Breaker.Enabled {
+			h.Breakers.Get(svc.name)
+			metrics.BreakerState.WithLabelValues(svc.name).Set(float64(breaker.Closed))
 		}
-	} else {
-		limit = 10
 	}
 
-	return int32(page), int32(limit), nil
+	return h, nil
+}
+
+// Drain makes the readiness check fail so no new traffic is routed here
+// while the server shuts down.
+func (h *Handler) Drain() {
+	h.draining.Store(true)
+}
+
+// Close closes all upstream connections.
+func (h *Handler) Close() error {
+	var errs []error
+	for _, conn := range h.conns {
+		if err := conn.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// bindUserID sets *userID to the authenticated caller. A value supplied in
+// the request body must match the caller. It aborts the request and returns
+// false on failure.
+func bindUserID(c *gin.Context, userID *string) bool {
+	principal, ok := middlewares.GetPrincipal(c)
+	if !ok || principal.UserID == "" {
+		response.AbortWithError(c, http.StatusUnauthorized, "Token does not identify a user")
+		return false
+	}
+	if *userID != "" && *userID != principal.UserID {
+		response.AbortWithError(c, http.StatusForbidden, "user_id does not match the authenticated user")
+		return false
+	}
+	*userID = principal.UserID
+	return true
 }
@@ -0,0 +1,188 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/Forum-service/Forum-api-gateway/api/breaker"
+	"github.com/Forum-service/Forum-api-gateway/api/metrics"
+	"github.com/Forum-service/Forum-api-gateway/api/middlewares"
+	"github.com/Forum-service/Forum-api-gateway/config"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// metricsInterceptor records call counts, latency and in-flight calls per
+// service and method.
+func metricsInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		service, name := serviceName(method), path.Base(method)
+		inFlight := metrics.GRPCInFlight.WithLabelValues(service)
+		inFlight.Inc()
+		start := time.Now()
+
+		err := invoker(ctx, method, req, reply, cc, opts...)
+
+		inFlight.Dec()
+		code := status.Code(err).String()
+		metrics.GRPCRequests.WithLabelValues(service, name, code).Inc()
+		metrics.GRPCDuration.WithLabelValues(service, name, code).Observe(time.Since(start).Seconds())
+		return err
+	}
+}
+
+// timeoutInterceptor bounds every call with a deadline. Per-method values
+// are looked up by full method name (/forum.PostService/GetPost) and then by
+// short name (GetPost); def applies otherwise. An earlier deadline already
+// on the context is kept.
+func timeoutInterceptor(def time.Duration, perMethod map[string]time.Duration) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		timeout, ok := perMethod[method]
+		if !ok {
+			timeout, ok = perMethod[path.Base(method)]
+		}
+		if !ok {
+			timeout = def
+		}
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// breakerInterceptor fails fast with Unavailable while the breaker of the
+// called service is open. The error carries a RetryInfo detail telling the
+// client when the service will be probed again.
+func breakerInterceptor(breakers *breaker.Set) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		// Health probes must see the real upstream state.
+		if serviceName(method) == healthpb.Health_ServiceDesc.ServiceName {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+		b := breakers.Get(serviceName(method))
+		done, retryAfter, err := b.Allow()
+		if err != nil {
+			metrics.BreakerRejected.WithLabelValues(b.Name()).Inc()
+			st := status.Newf(codes.Unavailable, "%s is unavailable: %v", b.Name(), err)
+			if withInfo, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(retryAfter)}); err == nil {
+				st = withInfo
+			}
+			return breakerOpenError{st}
+		}
+
+		err = invoker(ctx, method, req, reply, cc, opts...)
+		done(!upstreamFailure(err))
+		return err
+	}
+}
+
+// breakerOpenError is the Unavailable error of a call rejected by an open
+// breaker. Retrying it would only be rejected again.
+type breakerOpenError struct {
+	st *status.Status
+}
+
+func (e breakerOpenError) Error() string {
+	return e.st.Err().Error()
+}
+
+func (e breakerOpenError) GRPCStatus() *status.Status {
+	return e.st
+}
+
+// upstreamFailure reports whether err indicates an unhealthy upstream, as
+// opposed to a rejected request.
+func upstreamFailure(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted,
+		codes.Internal, codes.Unknown, codes.DataLoss:
+		return true
+	}
+	return false
+}
+
+// serviceName returns the service part of a full method name, e.g.
+// forum.PostService for /forum.PostService/GetPost.
+func serviceName(method string) string {
+	return strings.TrimPrefix(path.Dir(method), "/")
+}
+
+// retryInterceptor retries calls failing with Unavailable or
+// ResourceExhausted using exponential backoff with jitter. Only reads are
+// retried, and mutations carrying an idempotency key. Calls rejected by an
+// open breaker are not retried.
+func retryInterceptor(cfg config.RetryConfig) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if cfg.MaxAttempts <= 1 || !retryable(ctx, method) {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		var err error
+		for attempt := 1; ; attempt++ {
+			err = invoker(ctx, method, req, reply, cc, opts...)
+			if err == nil || attempt >= cfg.MaxAttempts || !retryableCode(status.Code(err)) ||
+				errors.As(err, new(breakerOpenError)) {
+				return err
+			}
+
+			wait := backoff(cfg, attempt)
+			metrics.GRPCRetries.WithLabelValues(serviceName(method), path.Base(method)).Inc()
+			log.Ctx(ctx).Warn().Err(err).Str("method", method).Int("attempt", attempt).Dur("backoff", wait).Msg("retrying upstream call")
+
+			timer := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return err
+			case <-timer.C:
+			}
+		}
+	}
+}
+
+// retryable reports whether a call may be sent more than once.
+func retryable(ctx context.Context, method string) bool {
+	if isReadMethod(method) {
+		return true
+	}
+	md, _ := metadata.FromOutgoingContext(ctx)
+	return len(md.Get(middlewares.IdempotencyKeyMetadata)) > 0
+}
+
+// isReadMethod reports whether method only reads data. All read RPCs of
+// the forum services are named Get* or Search*.
+func isReadMethod(method string) bool {
+	name := path.Base(method)
+	return strings.HasPrefix(name, "Get") || strings.HasPrefix(name, "Search")
+}
+
+func retryableCode(code codes.Code) bool {
+	return code == codes.Unavailable || code == codes.ResourceExhausted
+}
+
+// backoff returns the wait before the next attempt: the exponential delay
+// capped at MaxBackoff, with the upper half randomised.
+func backoff(cfg config.RetryConfig, attempt int) time.Duration {
+	d := float64(cfg.InitialBackoff)
+	for i := 1; i < attempt; i++ {
+		d *= cfg.Multiplier
+	}
+	if max := float64(cfg.MaxBackoff); max > 0 && d > max {
+		d = max
+	}
+	half := d / 2
+	return time.Duration(half + rand.Float64()*half)
+}
@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Forum-service/Forum-api-gateway/api/breaker"
+	"github.com/Forum-service/Forum-api-gateway/config"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRetryConsultsBreakerPerAttempt(t *testing.T) {
+	breakers := breaker.NewSet(breaker.Settings{
+		FailureRatio: 1,
+		MinRequests:  2,
+		CoolDown:     time.Minute,
+	})
+	retry := retryInterceptor(config.RetryConfig{
+		MaxAttempts:    5,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     time.Millisecond,
+		Multiplier:     2,
+	})
+	brk := breakerInterceptor(breakers)
+
+	calls := 0
+	upstream := func(context.Context, string, interface{}, interface{}, *grpc.ClientConn, ...grpc.CallOption) error {
+		calls++
+		return status.Error(codes.Unavailable, "down")
+	}
+	invoke := func() error {
+		return retry(context.Background(), "/forum.PostService/GetPost", nil, nil, nil,
+			func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+				return brk(ctx, method, req, reply, cc, upstream, opts...)
+			})
+	}
+
+	err := invoke()
+	if calls != 2 {
+		t.Errorf("upstream calls = %d, want 2: attempts after the breaker opened reached the upstream", calls)
+	}
+	if !errors.As(err, new(breakerOpenError)) || status.Code(err) != codes.Unavailable {
+		t.Errorf("err = %v, want the Unavailable error of the open breaker", err)
+	}
+	if s := breakers.Get("forum.PostService").State(); s != breaker.Open {
+		t.Errorf("breaker state = %s, want open", s)
+	}
+
+	calls = 0
+	if err := invoke(); !errors.As(err, new(breakerOpenError)) || calls != 0 {
+		t.Errorf("call while open: err = %v after %d upstream calls, want a rejection without calls", err, calls)
+	}
+}
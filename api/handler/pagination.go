@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Forum-service/Forum-api-gateway/api/response"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultLimit = 10
+	maxLimit     = 100
+)
+
+// pagination holds the page size bounds applied to list requests.
+type pagination struct {
+	defaultLimit int32
+	maxLimit     int32
+}
+
+func newPagination(def, max int) pagination {
+	if max <= 0 {
+		max = maxLimit
+	}
+	if def <= 0 {
+		def = defaultLimit
+	}
+	if def > max {
+		def = max
+	}
+	return pagination{defaultLimit: int32(def), maxLimit: int32(max)}
+}
+
+// ReadPageLimit reads the page and limit query parameters. Pages start at 1
+// and the limit defaults to DEFAULT_LIMIT and may not exceed MAX_LIMIT.
+func (h *Handler) ReadPageLimit(c *gin.Context) (int32, int32, error) {
+	page := int64(1)
+	if s := c.Query("page"); s != "" {
+		n, err := strconv.ParseInt(s, 10, 32)
+		if err != nil || n < 1 {
+			return 0, 0, fmt.Errorf("invalid page %q: must be a positive integer", s)
+		}
+		page = n
+	}
+
+	limit, err := h.readLimit(c)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// Keep the upstream offset, (page-1)*limit, within an int32.
+	if (page-1)*int64(limit) > 1<<31-1 {
+		return 0, 0, fmt.Errorf("invalid page %d: out of range", page)
+	}
+
+	return int32(page), limit, nil
+}
+
+// readLimit reads the limit query parameter.
+func (h *Handler) readLimit(c *gin.Context) (int32, error) {
+	s := c.Query("limit")
+	if s == "" {
+		return h.pagination.defaultLimit, nil
+	}
+	n, err := strconv.ParseInt(s, 10, 32)
+	if err != nil || n < 1 || n > int64(h.pagination.maxLimit) {
+		return 0, fmt.Errorf("invalid limit %q: must be between 1 and %d", s, h.pagination.maxLimit)
+	}
+	return int32(n), nil
+}
+
+// writePage responds with items wrapped in a response.Page and sets RFC 8288
+// Link headers to the neighbouring pages. total is the count reported by the
+// upstream; services that do not report it yet leave it at zero, in which
+// case has_next falls back to whether the page is full and no last link is
+// sent.
+func writePage[T any](c *gin.Context, items []T, page, limit int32, total int64) {
+	if items == nil {
+		items = []T{}
+	}
+
+	p, known := paginate(page, limit, total, len(items))
+
+	links := []string{pageLink(c, 1, limit, "first")}
+	if page > 1 {
+		links = append(links, pageLink(c, page-1, limit, "prev"))
+	}
+	if p.HasNext {
+		links = append(links, pageLink(c, page+1, limit, "next"))
+	}
+	if known {
+		last := (total + int64(limit) - 1) / int64(limit)
+		if last < 1 {
+			last = 1
+		}
+		links = append(links, pageLink(c, int32(last), limit, "last"))
+	}
+	c.Header("Link", strings.Join(links, ", "))
+
+	c.JSON(http.StatusOK, response.Page{Data: items, Pagination: p})
+}
+
+// paginate describes a page holding n items. known reports whether total
+// was reported by the upstream, see writePage.
+func paginate(page, limit int32, total int64, n int) (response.Pagination, bool) {
+	known := total > 0 || (page == 1 && n == 0)
+	hasNext := n == int(limit)
+	if known {
+		hasNext = int64(page)*int64(limit) < total
+	}
+	return response.Pagination{
+		Page:    page,
+		Limit:   limit,
+		Total:   total,
+		HasNext: hasNext,
+	}, known
+}
+
+// pageLink returns a Link header value pointing at another page of the
+// current request, preserving its other query parameters.
+func pageLink(c *gin.Context, page, limit int32, rel string) string {
+	u := *c.Request.URL
+	q := u.Query()
+	q.Set("page", strconv.Itoa(int(page)))
+	q.Set("limit", strconv.Itoa(int(limit)))
+	u.RawQuery = q.Encode()
+	return fmt.Sprintf("<%s>; rel=%q", u.RequestURI(), rel)
+}
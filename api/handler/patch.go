@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/Forum-service/Forum-api-gateway/api/response"
+	"github.com/gin-gonic/gin"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// Fields each resource may change through PATCH.
+var (
+	postPatchFields     = []string{"title", "body", "category_id"}
+	commentPatchFields  = []string{"body"}
+	tagPatchFields      = []string{"name"}
+	categoryPatchFields = []string{"name"}
+)
+
+// bindMergePatch reads a JSON Merge Patch (RFC 7396) into req, an update
+// request whose JSON names match the proto field names, and returns the
+// mask of the fields the patch sets. A field set to null is cleared. Only
+// the fields in patchable may appear. It aborts the request and returns
+// false on failure.
+func bindMergePatch(c *gin.Context, req interface{}, patchable []string) (*fieldmaskpb.FieldMask, bool) {
+	if ct := c.GetHeader("Content-Type"); ct != "" {
+		mediaType, _, err := mime.ParseMediaType(ct)
+		if err != nil || (mediaType != "application/merge-patch+json" && mediaType != "application/json") {
+			response.AbortWithError(c, http.StatusUnsupportedMediaType, "Content-Type must be application/merge-patch+json")
+			return nil, false
+		}
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		response.AbortWithError(c, http.StatusBadRequest, "Failed to read request body")
+		return nil, false
+	}
+	var patch map[string]json.RawMessage
+	if err := json.Unmarshal(body, &patch); err != nil || patch == nil {
+		response.AbortWithError(c, http.StatusBadRequest, "Request body must be a JSON object")
+		return nil, false
+	}
+	if len(patch) == 0 {
+		response.AbortWithError(c, http.StatusBadRequest, "Patch must set at least one of "+strings.Join(patchable, ", "))
+		return nil, false
+	}
+
+	mask := &fieldmaskpb.FieldMask{}
+	for field := range patch {
+		if !contains(patchable, field) {
+			response.AbortWithError(c, http.StatusBadRequest, fmt.Sprintf("Field %q cannot be patched, must be one of %s", field, strings.Join(patchable, ", ")))
+			return nil, false
+		}
+		mask.Paths = append(mask.Paths, field)
+	}
+	sort.Strings(mask.Paths)
+
+	if err := json.Unmarshal(body, req); err != nil {
+		response.AbortWithError(c, http.StatusBadRequest, err.Error())
+		return nil, false
+	}
+	return mask, true
+}
@@ -1,40 +1,53 @@
 package handler
 
 import (
+	"context"
+	"fmt"
 	"net/http"
+	"strings"
+	"sync"
 
+	"github.com/Forum-service/Forum-api-gateway/api/response"
+	"github.com/Forum-service/Forum-api-gateway/genproto/category"
+	"github.com/Forum-service/Forum-api-gateway/genproto/comment"
 	"github.com/Forum-service/Forum-api-gateway/genproto/post"
+	"github.com/Forum-service/Forum-api-gateway/genproto/posttag"
+	"github.com/Forum-service/Forum-api-gateway/genproto/tag"
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog/log"
+	"golang.org/x/sync/errgroup"
 )
 
 // CreatePost godoc
 // @Summary Create a new post
-// @Description Create a new post with given information
+// @Description Create a new post with given information. The author is taken from the token; a user_id in the body must match it.
 // @Tags post
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param post body post.CreatePostRequest true "Post information"
+// @Param Idempotency-Key header string false "Makes retries of the request safe, the first response is replayed"
 // @Success 201 {object} post.Post
-// @Failure 400 {object} string "Invalid request body"
-// @Failure 500 {object} string "Internal server error"
+// @Failure 400 {object} response.ErrorResponse "Invalid request body"
+// @Failure 403 {object} response.ErrorResponse "user_id does not match the token"
+// @Failure 409 {object} response.ErrorResponse "Idempotency-Key already used for a different request"
+// @Failure 425 {object} response.ErrorResponse "Request with the same Idempotency-Key still in progress"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
 // @Router /posts [post]
 func (h *Handler) CreatePost(c *gin.Context) {
 	var req post.CreatePostRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		log.Error().Err(err).Msg("failed to bind json")
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": err.Error(),
-		})
+		log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to bind json")
+		response.AbortWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !bindUserID(c, &req.UserId) {
 		return
 	}
 	resp, err := h.PostService.CreatePost(c.Request.Context(), &req)
 	if err != nil {
-		log.Error().Err(err).Msg("failed to create post")
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": err.Error(),
-		})
+		log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to create post")
+		response.AbortWithGRPCError(c, err)
 		return
 	}
 	c.JSON(http.StatusCreated, resp)
@@ -48,10 +61,13 @@ func (h *Handler) CreatePost(c *gin.Context) {
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "Post ID"
+// @Param If-None-Match header string false "ETag of a cached copy; 304 if it is still current"
 // @Success 200 {object} post.Post
-// @Failure 400 {object} string "Invalid request body"
-// @Failure 404 {object} string "Post item not found"
-// @Failure 500 {object} string "Internal server error"
+// @Header 200 {string} ETag "Version of the post, send it as If-Match to update it"
+// @Success 304 "Not modified"
+// @Failure 400 {object} response.ErrorResponse "Invalid request body"
+// @Failure 404 {object} response.ErrorResponse "Post item not found"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
 // @Router /posts/{id} [get]
 func (h *Handler) GetPostById(c *gin.Context) {
 	id := c.Param("id")
@@ -60,13 +76,11 @@ func (h *Handler) GetPostById(c *gin.Context) {
 		Id: id,
 	})
 	if err != nil {
-		log.Error().Err(err).Msg("failed to get post")
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": err.Error(),
-		})
+		log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to get post")
+		response.AbortWithGRPCError(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, resp)
+	writeVersioned(c, resp, resp.GetPost().GetUpdatedAt())
 }
 
 // UpdatePost godoc
@@ -78,30 +92,77 @@ func (h *Handler) GetPostById(c *gin.Context) {
 // @Security BearerAuth
 // @Param id path string true "Post ID"
 // @Param post body post.UpdatePostRequest true "Post information"
+// @Param If-Match header string false "ETag of the version being updated; the update fails with 412 if the post changed since"
 // @Success 200 {object} post.Post
-// @Failure 400 {object} string "Invalid request body"
-// @Failure 404 {object} string "Post item not found"
-// @Failure 500 {object} string "Internal server error"
+// @Header 200 {string} ETag "New version of the post"
+// @Failure 400 {object} response.ErrorResponse "Invalid request body or If-Match header"
+// @Failure 403 {object} response.ErrorResponse "Caller is neither the author nor a moderator"
+// @Failure 404 {object} response.ErrorResponse "Post item not found"
+// @Failure 412 {object} response.ErrorResponse "The post was modified since the If-Match version"
+// @Failure 428 {object} response.ErrorResponse "If-Match is required"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
 // @Router /posts/{id} [put]
 func (h *Handler) UpdatePost(c *gin.Context) {
 	var req post.UpdatePostRequest
-	id := c.Param("id")
-	req.Id = id
 	if err := c.ShouldBindJSON(&req); err != nil {
-		log.Error().Err(err).Msg("failed to bind json")
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": err.Error(),
-		})
+		log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to bind json")
+		response.AbortWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	// Set after binding so an id in the body cannot redirect the update
+	// away from the resource authorized by path.
+	req.Id = c.Param("id")
+	if !h.bindIfMatch(c, &req.ExpectedUpdatedAt) {
 		return
 	}
 	resp, err := h.PostService.UpdatePost(c.Request.Context(), &req)
 	if err != nil {
-		log.Error().Err(err).Msg("failed to update post")
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": err.Error(),
-		})
+		log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to update post")
+		abortWithUpdateError(c, err, req.ExpectedUpdatedAt)
 		return
 	}
+	setVersion(c, resp.GetPost().GetUpdatedAt())
+	c.JSON(http.StatusOK, resp)
+}
+
+// PatchPost godoc
+// @Summary Partially update a post
+// @Description Change only the fields present in a JSON Merge Patch (RFC 7396): title, body, category_id. A field set to null is cleared.
+// @Tags post
+// @Accept json,application/merge-patch+json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Post ID"
+// @Param patch body object true "Fields to change: title, body, category_id"
+// @Param If-Match header string false "ETag of the version being updated; the update fails with 412 if the post changed since"
+// @Success 200 {object} post.Post
+// @Header 200 {string} ETag "New version of the post"
+// @Failure 400 {object} response.ErrorResponse "Invalid patch or If-Match header"
+// @Failure 403 {object} response.ErrorResponse "Caller is neither the author nor a moderator"
+// @Failure 404 {object} response.ErrorResponse "Post item not found"
+// @Failure 412 {object} response.ErrorResponse "The post was modified since the If-Match version"
+// @Failure 415 {object} response.ErrorResponse "Unsupported Content-Type"
+// @Failure 428 {object} response.ErrorResponse "If-Match is required"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /posts/{id} [patch]
+func (h *Handler) PatchPost(c *gin.Context) {
+	var req post.UpdatePostRequest
+	mask, ok := bindMergePatch(c, &req, postPatchFields)
+	if !ok {
+		return
+	}
+	req.Id = c.Param("id")
+	req.UpdateMask = mask
+	if !h.bindIfMatch(c, &req.ExpectedUpdatedAt) {
+		return
+	}
+	resp, err := h.PostService.UpdatePost(c.Request.Context(), &req)
+	if err != nil {
+		log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to patch post")
+		abortWithUpdateError(c, err, req.ExpectedUpdatedAt)
+		return
+	}
+	setVersion(c, resp.GetPost().GetUpdatedAt())
 	c.JSON(http.StatusOK, resp)
 }
 
@@ -114,18 +175,17 @@ func (h *Handler) UpdatePost(c *gin.Context) {
 // @Security BearerAuth
 // @Param id path string true "Post ID"
 // @Success 200 {object} post.DeletePostResponse
-// @Failure 400 {object} string "Invalid request body"
-// @Failure 404 {object} string "Post item not found"
-// @Failure 500 {object} string "Internal server error"
+// @Failure 400 {object} response.ErrorResponse "Invalid request body"
+// @Failure 403 {object} response.ErrorResponse "Caller is neither the author nor a moderator"
+// @Failure 404 {object} response.ErrorResponse "Post item not found"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
 // @Router /posts/{id} [delete]
 func (h *Handler) DeletePost(c *gin.Context) {
 	id := c.Param("id")
 	resp, err := h.PostService.DeletePost(c.Request.Context(), &post.DeletePostRequest{Id: id})
 	if err != nil {
-		log.Error().Err(err).Msg("failed to delete post")
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": err.Error(),
-		})
+		log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to delete post")
+		response.AbortWithGRPCError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, resp)
@@ -133,45 +193,303 @@ func (h *Handler) DeletePost(c *gin.Context) {
 
 // GetAllPosts godoc
 // @Summary Get all posts
-// @Description Retrieve a list of all posts with optional filtering and pagination
+// @Description Retrieve a list of all posts with optional filtering and pagination. With the cursor parameter the newest posts come first and the response is a response.CursorPage carrying next_cursor.
 // @Tags post
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param page query int false "Page number"
 // @Param limit query int false "Number of posts per page"
+// @Param cursor query string false "Opaque cursor from pagination.next_cursor; switches to keyset pagination, pass it empty for the first page"
 // @Param user_id query string false "Filter by user ID"
 // @Param title query string false "Filter by title"
 // @Param category_id query string false "Filter by category ID"
 // @Param body query string false "Filter by body content (partial match)"
-// @Success 200 {object} post.GetAllPostsResponse
-// @Failure 500 {object} string "Internal server error"
+// @Param sort query string false "Comma separated sort fields, prefix with - for descending: created_at, updated_at, title"
+// @Param created_after query string false "Only items created at or after this RFC 3339 time"
+// @Param created_before query string false "Only items created before this RFC 3339 time"
+// @Param include_deleted query bool false "Include soft deleted items (admins only)"
+// @Success 200 {object} response.Page{data=[]post.Post}
+// @Failure 400 {object} response.ErrorResponse "Invalid query parameters"
+// @Failure 403 {object} response.ErrorResponse "include_deleted requires an admin"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
 // @Router /posts [get]
 func (h *Handler) GetAllPosts(c *gin.Context) {
-	var (
-		req post.GetAllPostsRequest
-		err error
-	)
-
+	var req post.GetAllPostsRequest
 	req.UserId = c.Query("user_id")
-	req.Title = c.Query("title")
 	req.CategoryId = c.Query("category_id")
+	h.listPosts(c, &req, "posts")
+}
+
+// GetCategoryPosts godoc
+// @Summary Get the posts of a category
+// @Description Retrieve the posts of a category with the same filtering, sorting and pagination as GET /posts
+// @Tags post
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Category ID"
+// @Param page query int false "Page number"
+// @Param limit query int false "Number of posts per page"
+// @Param cursor query string false "Opaque cursor from pagination.next_cursor; switches to keyset pagination, pass it empty for the first page"
+// @Param title query string false "Filter by title"
+// @Param body query string false "Filter by body content (partial match)"
+// @Param sort query string false "Comma separated sort fields, prefix with - for descending: created_at, updated_at, title"
+// @Param created_after query string false "Only items created at or after this RFC 3339 time"
+// @Param created_before query string false "Only items created before this RFC 3339 time"
+// @Param include_deleted query bool false "Include soft deleted items (admins only)"
+// @Success 200 {object} response.Page{data=[]post.Post}
+// @Failure 400 {object} response.ErrorResponse "Invalid query parameters"
+// @Failure 403 {object} response.ErrorResponse "include_deleted requires an admin"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /categories/{id}/posts [get]
+func (h *Handler) GetCategoryPosts(c *gin.Context) {
+	req := post.GetAllPostsRequest{CategoryId: c.Param("id")}
+	h.listPosts(c, &req, "categories/"+req.CategoryId+"/posts")
+}
+
+// GetUserPosts godoc
+// @Summary Get the posts of a user
+// @Description Retrieve the posts written by a user with the same filtering, sorting and pagination as GET /posts
+// @Tags post
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param page query int false "Page number"
+// @Param limit query int false "Number of posts per page"
+// @Param cursor query string false "Opaque cursor from pagination.next_cursor; switches to keyset pagination, pass it empty for the first page"
+// @Param title query string false "Filter by title"
+// @Param category_id query string false "Filter by category ID"
+// @Param body query string false "Filter by body content (partial match)"
+// @Param sort query string false "Comma separated sort fields, prefix with - for descending: created_at, updated_at, title"
+// @Param created_after query string false "Only items created at or after this RFC 3339 time"
+// @Param created_before query string false "Only items created before this RFC 3339 time"
+// @Param include_deleted query bool false "Include soft deleted items (admins only)"
+// @Success 200 {object} response.Page{data=[]post.Post}
+// @Failure 400 {object} response.ErrorResponse "Invalid query parameters"
+// @Failure 403 {object} response.ErrorResponse "include_deleted requires an admin"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /users/{id}/posts [get]
+func (h *Handler) GetUserPosts(c *gin.Context) {
+	req := post.GetAllPostsRequest{UserId: c.Param("id")}
+	req.CategoryId = c.Query("category_id")
+	h.listPosts(c, &req, "users/"+req.UserId+"/posts")
+}
+
+// listPosts completes req with the filters, sorting and pagination shared
+// by all post listings, calls the post service and writes the page.
+// listing names the listing in the cursors it issues.
+func (h *Handler) listPosts(c *gin.Context, req *post.GetAllPostsRequest, listing string) {
+	var err error
+
+	req.Title = c.Query("title")
 	req.Body = c.Query("body")
-	req.Page, req.Limit, err = ReadPageLimit(c)
+	req.Sort, err = readSort(c, postSortFields)
+	if err != nil {
+		log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to read sort")
+		response.AbortWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	req.CreatedAfter, req.CreatedBefore, err = readCreatedRange(c)
+	if err != nil {
+		log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to read created range")
+		response.AbortWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !h.bindIncludeDeleted(c, &req.IncludeDeleted) {
+		return
+	}
+
+	cursorMode := usesCursor(c)
+	if cursorMode {
+		var after cursor
+		after, req.Limit, err = h.ReadCursor(c, listing)
+		if err != nil {
+			log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to read cursor")
+			response.AbortWithError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		req.AfterCreatedAt, req.AfterId = after.CreatedAt, after.ID
+		req.Sort = cursorSort
+		req.Page = 1
+		req.Limit++
+	} else {
+		req.Page, req.Limit, err = h.ReadPageLimit(c)
+		if err != nil {
+			log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to read page limit")
+			response.AbortWithError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	resp, err := h.PostService.GetAllPosts(c.Request.Context(), req)
 	if err != nil {
-		log.Error().Err(err).Msg("failed to read page limit")
-		c.JSON(http.StatusBadGateway, gin.H{
-			"error": err.Error(),
+		log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to get posts")
+		response.AbortWithGRPCError(c, err)
+		return
+	}
+	if cursorMode {
+		writeCursorPage(c, h.cursors, listing, resp.GetPosts(), req.Limit-1, func(v *post.Post) (string, string) {
+			return v.GetCreatedAt(), v.GetId()
 		})
 		return
 	}
-	resp, err := h.PostService.GetAllPosts(c.Request.Context(), &req)
+	writePage(c, resp.GetPosts(), req.Page, req.Limit, resp.GetTotal())
+}
+
+// PostOwner returns the author of the post addressed by the id path parameter.
+// It is used by the authorization policy for ownership checks.
+func (h *Handler) PostOwner(c *gin.Context) (string, error) {
+	resp, err := h.PostService.GetPost(c.Request.Context(), &post.GetPostRequest{
+		Id: c.Param("id"),
+	})
+	if err != nil {
+		return "", err
+	}
+	return resp.GetPost().GetUserId(), nil
+}
+
+// postExpansions lists the parts GET /posts/{id}/full can add to a post.
+var postExpansions = []string{"category", "tags", "comments"}
+
+// PostDetail is the composed document returned by GET /posts/{id}/full.
+// Parts that were not expanded or could not be loaded are left out, the
+// latter reported in errors. Expanded tags are always an array, empty when
+// the post has none.
+type PostDetail struct {
+	Post     *post.Post                    `json:"post"`
+	Category *category.Category            `json:"category,omitempty"`
+	Tags     *[]*tag.Tag                   `json:"tags,omitempty"`
+	Comments *response.Page                `json:"comments,omitempty"`
+	Errors   map[string]response.ErrorBody `json:"errors,omitempty"`
+}
+
+// GetPostFull godoc
+// @Summary Get a post with its category, tags and comments
+// @Description Retrieve a post together with its category, its tags and the first page of its comments in one document. Only the post is required; parts that fail to load are reported in errors.
+// @Tags post
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Post ID"
+// @Param expand query string false "Comma separated parts to include: category, tags, comments (default all)"
+// @Success 200 {object} handler.PostDetail
+// @Failure 400 {object} response.ErrorResponse "Invalid query parameters"
+// @Failure 404 {object} response.ErrorResponse "Post item not found"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /posts/{id}/full [get]
+func (h *Handler) GetPostFull(c *gin.Context) {
+	id := c.Param("id")
+	expand, err := readExpand(c)
 	if err != nil {
-		log.Error().Err(err).Msg("failed to get posts")
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": err.Error(),
+		log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to read expand")
+		response.AbortWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var (
+		mu     sync.Mutex
+		detail PostDetail
+	)
+	// fail records a non-critical part that could not be loaded.
+	fail := func(ctx context.Context, part string, err error) {
+		log.Ctx(ctx).Warn().Err(err).Str("part", part).Msg("failed to load part of post")
+		mu.Lock()
+		defer mu.Unlock()
+		if detail.Errors == nil {
+			detail.Errors = map[string]response.ErrorBody{}
+		}
+		detail.Errors[part] = response.NewErrorBody(err)
+	}
+
+	g, ctx := errgroup.WithContext(c.Request.Context())
+	g.Go(func() error {
+		resp, err := h.PostService.GetPost(ctx, &post.GetPostRequest{Id: id})
+		if err != nil {
+			return err
+		}
+		detail.Post = resp.GetPost()
+		if categoryID := detail.Post.GetCategoryId(); expand["category"] && categoryID != "" {
+			g.Go(func() error {
+				resp, err := h.CategoryService.GetCategory(ctx, &category.GetCategoryRequest{Id: categoryID})
+				if err != nil {
+					fail(ctx, "category", err)
+					return nil
+				}
+				detail.Category = resp.GetCategory()
+				return nil
+			})
+		}
+		return nil
+	})
+	if expand["tags"] {
+		g.Go(func() error {
+			resp, err := h.PostTagService.GetAllPostTags(ctx, &posttag.GetAllPostTagsRequest{
+				PostId: id,
+				Page:   1,
+				Limit:  h.pagination.maxLimit,
+			})
+			var tags []*tag.Tag
+			if err == nil {
+				tags, err = h.tagsOf(ctx, resp.GetPostTags())
+			}
+			if err != nil {
+				fail(ctx, "tags", err)
+				return nil
+			}
+			if tags == nil {
+				tags = []*tag.Tag{}
+			}
+			detail.Tags = &tags
+			return nil
 		})
+	}
+	if expand["comments"] {
+		g.Go(func() error {
+			req := &comment.GetAllCommentsRequest{PostId: id, Page: 1, Limit: h.pagination.defaultLimit}
+			resp, err := h.CommentService.GetAllComments(ctx, req)
+			if err != nil {
+				fail(ctx, "comments", err)
+				return nil
+			}
+			comments := resp.GetComments()
+			if comments == nil {
+				comments = []*comment.Comment{}
+			}
+			page, _ := paginate(req.Page, req.Limit, resp.GetTotal(), len(comments))
+			detail.Comments = &response.Page{Data: comments, Pagination: page}
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to get post")
+		response.AbortWithGRPCError(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, resp)
+	c.JSON(http.StatusOK, detail)
+}
+
+// readExpand parses the expand query parameter. All parts are expanded
+// when it is missing.
+func readExpand(c *gin.Context) (map[string]bool, error) {
+	expand := map[string]bool{}
+	s, ok := c.GetQuery("expand")
+	if !ok {
+		for _, part := range postExpansions {
+			expand[part] = true
+		}
+		return expand, nil
+	}
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if !contains(postExpansions, part) {
+			return nil, fmt.Errorf("invalid expand %q: must be one of %s", part, strings.Join(postExpansions, ", "))
+		}
+		expand[part] = true
+	}
+	return expand, nil
 }
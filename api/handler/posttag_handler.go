@@ -1,11 +1,21 @@
 package handler
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
 	"net/http"
 
 	"github.com/rs/zerolog/log"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
+	"github.com/Forum-service/Forum-api-gateway/api/response"
+	"github.com/Forum-service/Forum-api-gateway/genproto/post"
 	"github.com/Forum-service/Forum-api-gateway/genproto/posttag"
+	"github.com/Forum-service/Forum-api-gateway/genproto/tag"
 	"github.com/gin-gonic/gin"
 )
 
@@ -17,25 +27,25 @@ import (
 // @Produce json
 // @Security BearerAuth
 // @Param posttag body posttag.CreatePostTagRequest true "PostTag information"
+// @Param Idempotency-Key header string false "Makes retries of the request safe, the first response is replayed"
 // @Success 201 {object} posttag.PostTag
-// @Failure 400 {object} string "Invalid request body"
-// @Failure 500 {object} string "Internal server error"
+// @Failure 400 {object} response.ErrorResponse "Invalid request body"
+// @Failure 403 {object} response.ErrorResponse "Caller is neither the author of the post nor a moderator"
+// @Failure 409 {object} response.ErrorResponse "Idempotency-Key already used for a different request"
+// @Failure 425 {object} response.ErrorResponse "Request with the same Idempotency-Key still in progress"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
 // @Router /posttags [post]
 func (h *Handler) CreatePostTag(c *gin.Context) {
 	var req posttag.CreatePostTagRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		log.Error().Err(err).Msg("failed to bind json")
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": err.Error(),
-		})
+		log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to bind json")
+		response.AbortWithError(c, http.StatusBadRequest, err.Error())
 		return
 	}
 	resp, err := h.PostTagService.CreatePostTag(c.Request.Context(), &req)
 	if err != nil {
-		log.Error().Err(err).Msg("failed to create post-tag relationship")
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": err.Error(),
-		})
+		log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to create post-tag relationship")
+		response.AbortWithGRPCError(c, err)
 		return
 	}
 	c.JSON(http.StatusCreated, resp)
@@ -48,27 +58,22 @@ func (h *Handler) CreatePostTag(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param posttag body posttag.DeletePostTagRequest true "PostTag information"
+// @Param postid path string true "Post ID"
+// @Param tagid path string true "Tag ID"
 // @Success 200 {object} posttag.DeletePostTagResponse
-// @Failure 400 {object} string "Invalid request body"
-// @Failure 404 {object} string "Post_tag item not found"
-// @Failure 500 {object} string "Internal server error"
-// @Router /posttags [delete]
+// @Failure 403 {object} response.ErrorResponse "Caller is neither the author of the post nor a moderator"
+// @Failure 404 {object} response.ErrorResponse "Post_tag item not found"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /posttags/{postid}/{tagid} [delete]
 func (h *Handler) DeletePostTag(c *gin.Context) {
-	var req posttag.DeletePostTagRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		log.Error().Err(err).Msg("failed to bind json")
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": err.Error(),
-		})
-		return
+	req := posttag.DeletePostTagRequest{
+		PostId: c.Param("postid"),
+		TagId:  c.Param("tagid"),
 	}
 	resp, err := h.PostTagService.DeletePostTag(c.Request.Context(), &req)
 	if err != nil {
-		log.Error().Err(err).Msg("failed to delete post-tag relationship")
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": err.Error(),
-		})
+		log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to delete post-tag relationship")
+		response.AbortWithGRPCError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, resp)
@@ -85,8 +90,9 @@ func (h *Handler) DeletePostTag(c *gin.Context) {
 // @Param limit query int false "Number of post-tag relationships per page"
 // @Param post_id query string false "Filter by post ID"
 // @Param tag_id query string false "Filter by tag ID"
-// @Success 200 {object} posttag.GetAllPostTagsResponse
-// @Failure 500 {object} string "Internal server error"
+// @Success 200 {object} response.Page{data=[]posttag.PostTag}
+// @Failure 400 {object} response.ErrorResponse "Invalid query parameters"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
 // @Router /posttags [get]
 func (h *Handler) GetAllPostTags(c *gin.Context) {
 	var (
@@ -96,23 +102,19 @@ func (h *Handler) GetAllPostTags(c *gin.Context) {
 	req.PostId = c.Query("post_id")
 	req.TagId = c.Query("tag_id")
 
-	req.Page, req.Limit, err = ReadPageLimit(c)
+	req.Page, req.Limit, err = h.ReadPageLimit(c)
 	if err != nil {
-		log.Error().Err(err).Msg("failed to read page limit")
-		c.JSON(http.StatusBadGateway, gin.H{
-			"error": err.Error(),
-		})
+		log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to read page limit")
+		response.AbortWithError(c, http.StatusBadRequest, err.Error())
 		return
 	}
 	resp, err := h.PostTagService.GetAllPostTags(c.Request.Context(), &req)
 	if err != nil {
-		log.Error().Err(err).Msg("failed to get post-tag relationships")
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": err.Error(),
-		})
+		log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to get post-tag relationships")
+		response.AbortWithGRPCError(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, resp)
+	writePage(c, resp.GetPostTags(), req.Page, req.Limit, resp.GetTotal())
 }
 
 // GetPostsByTag godoc
@@ -125,10 +127,10 @@ func (h *Handler) GetAllPostTags(c *gin.Context) {
 // @Param tag_id path string true "Tag ID"
 // @Param page query int false "Page number"
 // @Param limit query int false "Number of posts per page"
-// @Success 200 {object} posttag.GetPostsByTagResponse
-// / @Failure 400 {object} string "Invalid request body"
-// @Failure 404 {object} string "Posttag item not found"
-// @Failure 500 {object} string "Internal server error"
+// @Success 200 {object} response.Page{data=[]post.Post}
+// @Failure 400 {object} response.ErrorResponse "Invalid query parameters"
+// @Failure 404 {object} response.ErrorResponse "Posttag item not found"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
 // @Router /posttags/{tag_id}/posts [get]
 func (h *Handler) GetPostsByTag(c *gin.Context) {
 	var (
@@ -137,21 +139,186 @@ func (h *Handler) GetPostsByTag(c *gin.Context) {
 	)
 	req.TagId = c.Param("tag_id")
 
-	req.Page, req.Limit, err = ReadPageLimit(c)
+	req.Page, req.Limit, err = h.ReadPageLimit(c)
 	if err != nil {
-		log.Error().Err(err).Msg("failed to read page limit")
-		c.JSON(http.StatusBadGateway, gin.H{
-			"error": err.Error(),
-		})
+		log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to read page limit")
+		response.AbortWithError(c, http.StatusBadRequest, err.Error())
 		return
 	}
 	resp, err := h.PostTagService.GetPostsByTag(c.Request.Context(), &req)
 	if err != nil {
-		log.Error().Err(err).Msg("failed to get posts by tag")
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": err.Error(),
-		})
+		log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to get posts by tag")
+		response.AbortWithGRPCError(c, err)
+		return
+	}
+	writePage(c, resp.GetPosts(), req.Page, req.Limit, resp.GetTotal())
+}
+
+// AddPostTagRequest is the body of POST /posts/{id}/tags.
+type AddPostTagRequest struct {
+	TagID string `json:"tag_id" binding:"required"`
+}
+
+// GetPostTags godoc
+// @Summary Get the tags of a post
+// @Description Retrieve the tags attached to a post
+// @Tags posttag
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Post ID"
+// @Param page query int false "Page number"
+// @Param limit query int false "Number of tags per page"
+// @Success 200 {object} response.Page{data=[]tag.Tag}
+// @Failure 400 {object} response.ErrorResponse "Invalid query parameters"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /posts/{id}/tags [get]
+func (h *Handler) GetPostTags(c *gin.Context) {
+	var (
+		req posttag.GetAllPostTagsRequest
+		err error
+	)
+	req.PostId = c.Param("id")
+
+	req.Page, req.Limit, err = h.ReadPageLimit(c)
+	if err != nil {
+		log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to read page limit")
+		response.AbortWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := h.PostTagService.GetAllPostTags(c.Request.Context(), &req)
+	if err != nil {
+		log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to get post-tag relationships")
+		response.AbortWithGRPCError(c, err)
+		return
+	}
+	tags, err := h.tagsOf(c.Request.Context(), resp.GetPostTags())
+	if err != nil {
+		log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to get tags")
+		response.AbortWithGRPCError(c, err)
+		return
+	}
+	writePage(c, tags, req.Page, req.Limit, resp.GetTotal())
+}
+
+// AddPostTag godoc
+// @Summary Tag a post
+// @Description Attach a tag to a post
+// @Tags posttag
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Post ID"
+// @Param tag body handler.AddPostTagRequest true "Tag to attach"
+// @Param Idempotency-Key header string false "Makes retries of the request safe, the first response is replayed"
+// @Success 201 {object} posttag.CreatePostTagResponse
+// @Failure 400 {object} response.ErrorResponse "Invalid request body"
+// @Failure 403 {object} response.ErrorResponse "Not the author of the post"
+// @Failure 409 {object} response.ErrorResponse "Post already has the tag, or Idempotency-Key already used for a different request"
+// @Failure 425 {object} response.ErrorResponse "Request with the same Idempotency-Key still in progress"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /posts/{id}/tags [post]
+func (h *Handler) AddPostTag(c *gin.Context) {
+	var body AddPostTagRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to bind json")
+		response.AbortWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := h.PostTagService.CreatePostTag(c.Request.Context(), &posttag.CreatePostTagRequest{
+		PostId: c.Param("id"),
+		TagId:  body.TagID,
+	})
+	if err != nil {
+		log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to create post-tag relationship")
+		response.AbortWithGRPCError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, resp)
+}
+
+// RemovePostTag godoc
+// @Summary Untag a post
+// @Description Detach a tag from a post
+// @Tags posttag
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Post ID"
+// @Param tag_id path string true "Tag ID"
+// @Success 200 {object} posttag.DeletePostTagResponse
+// @Failure 403 {object} response.ErrorResponse "Not the author of the post"
+// @Failure 404 {object} response.ErrorResponse "Post does not have the tag"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /posts/{id}/tags/{tag_id} [delete]
+func (h *Handler) RemovePostTag(c *gin.Context) {
+	resp, err := h.PostTagService.DeletePostTag(c.Request.Context(), &posttag.DeletePostTagRequest{
+		PostId: c.Param("id"),
+		TagId:  c.Param("tag_id"),
+	})
+	if err != nil {
+		log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to delete post-tag relationship")
+		response.AbortWithGRPCError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, resp)
 }
+
+// maxTagLookups bounds the concurrent GetTag calls of one request.
+const maxTagLookups = 8
+
+// tagsOf looks up the tags of the given relationships concurrently,
+// keeping their order. Tags deleted in the meantime are left out.
+func (h *Handler) tagsOf(ctx context.Context, rels []*posttag.PostTag) ([]*tag.Tag, error) {
+	tags := make([]*tag.Tag, len(rels))
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(maxTagLookups)
+	for i, rel := range rels {
+		g.Go(func() error {
+			resp, err := h.TagService.GetTag(ctx, &tag.GetTagRequest{Id: rel.GetTagId()})
+			if status.Code(err) == codes.NotFound {
+				return nil
+			}
+			tags[i] = resp.GetTag()
+			return err
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	found := tags[:0]
+	for _, t := range tags {
+		if t != nil {
+			found = append(found, t)
+		}
+	}
+	return found, nil
+}
+
+// PostTagOwner returns the author of the post a post-tag relationship
+// belongs to, addressed by the postid path parameter or else the post_id
+// field of the body, which is left in place for the handler. It is used by
+// the authorization policy for ownership checks.
+func (h *Handler) PostTagOwner(c *gin.Context) (string, error) {
+	postID := c.Param("postid")
+	if postID == "" {
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			response.AbortWithError(c, http.StatusBadRequest, "Failed to read request body")
+			return "", err
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		var req posttag.CreatePostTagRequest
+		if json.Unmarshal(body, &req) != nil || req.GetPostId() == "" {
+			return "", nil
+		}
+		postID = req.GetPostId()
+	}
+
+	resp, err := h.PostService.GetPost(c.Request.Context(), &post.GetPostRequest{Id: postID})
+	if err != nil {
+		return "", err
+	}
+	return resp.GetPost().GetUserId(), nil
+}
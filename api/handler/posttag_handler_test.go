@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Forum-service/Forum-api-gateway/genproto/posttag"
+	"github.com/Forum-service/Forum-api-gateway/genproto/tag"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// tagGetter serves GetTag for every id but "gone", tracking the highest
+// number of concurrent calls.
+type tagGetter struct {
+	tag.TagServiceClient
+	inFlight, peak atomic.Int32
+}
+
+func (g *tagGetter) GetTag(_ context.Context, req *tag.GetTagRequest, _ ...grpc.CallOption) (*tag.GetTagResponse, error) {
+	n := g.inFlight.Add(1)
+	defer g.inFlight.Add(-1)
+	for {
+		peak := g.peak.Load()
+		if n <= peak || g.peak.CompareAndSwap(peak, n) {
+			break
+		}
+	}
+	time.Sleep(time.Millisecond)
+	if req.Id == "gone" {
+		return nil, status.Error(codes.NotFound, "tag not found")
+	}
+	return &tag.GetTagResponse{Tag: &tag.Tag{Id: req.Id}}, nil
+}
+
+func TestTagsOfBoundsLookups(t *testing.T) {
+	tags := &tagGetter{}
+	h := &Handler{TagService: tags}
+
+	var rels []*posttag.PostTag
+	for i := 0; i < 50; i++ {
+		id := fmt.Sprint(i)
+		if i == 3 {
+			id = "gone"
+		}
+		rels = append(rels, &posttag.PostTag{TagId: id})
+	}
+	got, err := h.tagsOf(context.Background(), rels)
+	if err != nil {
+		t.Fatalf("tagsOf: %v", err)
+	}
+
+	if len(got) != 49 {
+		t.Fatalf("tags = %d, want 49 without the deleted one", len(got))
+	}
+	for i, tg := range got {
+		want := i
+		if i >= 3 {
+			want++
+		}
+		if tg.GetId() != fmt.Sprint(want) {
+			t.Fatalf("tag %d = %s, want %d: order not kept", i, tg.GetId(), want)
+		}
+	}
+	if peak := tags.peak.Load(); peak > maxTagLookups {
+		t.Errorf("concurrent lookups = %d, want at most %d", peak, maxTagLookups)
+	}
+}
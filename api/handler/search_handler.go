@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Forum-service/Forum-api-gateway/api/response"
+	"github.com/Forum-service/Forum-api-gateway/genproto/search"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+const maxSearchQueryLength = 200
+
+// searchTypes lists the resource types a search can be restricted to.
+var searchTypes = []string{"post", "comment", "tag", "category"}
+
+// Search godoc
+// @Summary Search the forum
+// @Description Full-text search across posts, comments, tags and categories. Results are ranked by relevance and carry a snippet with the matching terms wrapped in <mark></mark>.
+// @Tags search
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param q query string true "Search terms"
+// @Param type query string false "Comma separated result types: post, comment, tag, category"
+// @Param page query int false "Page number"
+// @Param limit query int false "Number of results per page"
+// @Success 200 {object} response.Page{data=[]search.SearchResult}
+// @Failure 400 {object} response.ErrorResponse "Invalid query parameters"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /search [get]
+func (h *Handler) Search(c *gin.Context) {
+	var (
+		req search.SearchRequest
+		err error
+	)
+
+	req.Query = strings.TrimSpace(c.Query("q"))
+	if req.Query == "" {
+		response.AbortWithError(c, http.StatusBadRequest, "q is required")
+		return
+	}
+	if utf8.RuneCountInString(req.Query) > maxSearchQueryLength {
+		response.AbortWithError(c, http.StatusBadRequest, fmt.Sprintf("q must be at most %d characters", maxSearchQueryLength))
+		return
+	}
+	req.Types, err = readSearchTypes(c)
+	if err != nil {
+		log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to read search types")
+		response.AbortWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	req.Page, req.Limit, err = h.ReadPageLimit(c)
+	if err != nil {
+		log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to read page limit")
+		response.AbortWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := h.SearchService.Search(c.Request.Context(), &req)
+	if err != nil {
+		log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to search")
+		response.AbortWithGRPCError(c, err)
+		return
+	}
+	writePage(c, resp.GetResults(), req.Page, req.Limit, resp.GetTotal())
+}
+
+// readSearchTypes parses the type query parameter, a comma separated list
+// of result types.
+func readSearchTypes(c *gin.Context) ([]string, error) {
+	s := c.Query("type")
+	if s == "" {
+		return nil, nil
+	}
+
+	var types []string
+	for _, t := range strings.Split(s, ",") {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if !contains(searchTypes, t) {
+			return nil, fmt.Errorf("invalid type %q: must be one of %s", t, strings.Join(searchTypes, ", "))
+		}
+		if !contains(types, t) {
+			types = append(types, t)
+		}
+	}
+	return types, nil
+}
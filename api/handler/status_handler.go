@@ -0,0 +1,145 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/Forum-service/Forum-api-gateway/config"
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+type healthConfig struct {
+	// checkUpstream enables grpc.health.v1 checks against each upstream.
+	checkUpstream bool
+	// timeout bounds the readiness checks.
+	timeout time.Duration
+}
+
+// UpstreamStatus describes the connection to one upstream service.
+type UpstreamStatus struct {
+	Service string `json:"service"`
+	Target  string `json:"target"`
+	State   string `json:"state"`
+	Health  string `json:"health,omitempty"`
+	Ready   bool   `json:"ready"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Healthz reports that the process is alive.
+func (h *Handler) Healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Readyz reports whether the gateway is ready for traffic: it is not
+// shutting down, every upstream connection is READY and, when enabled, the
+// upstream grpc.health.v1 check reports SERVING.
+func (h *Handler) Readyz(c *gin.Context) {
+	if h.draining.Load() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "draining"})
+		return
+	}
+	upstreams, ready := h.checkUpstreams(c.Request.Context(), true)
+	code, state := http.StatusOK, "ready"
+	if !ready {
+		code, state = http.StatusServiceUnavailable, "not ready"
+	}
+	c.JSON(code, gin.H{
+		"status":    state,
+		"upstreams": upstreams,
+	})
+}
+
+// Status reports build information, uptime, upstream connection state and
+// circuit breaker state.
+func (h *Handler) Status(c *gin.Context) {
+	upstreams, ready := h.checkUpstreams(c.Request.Context(), false)
+	c.JSON(http.StatusOK, gin.H{
+		"version":    config.Version,
+		"commit":     config.Commit,
+		"build_time": config.BuildTime,
+		"started_at": h.startedAt.UTC(),
+		"uptime":     time.Since(h.startedAt).Round(time.Second).String(),
+		"ready":      ready && !h.draining.Load(),
+		"draining":   h.draining.Load(),
+		"upstreams":  upstreams,
+		"breakers":   h.Breakers.Snapshots(),
+	})
+}
+
+// BreakerStatus reports the state and counters of every upstream circuit
+// breaker.
+func (h *Handler) BreakerStatus(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"breakers": h.Breakers.Snapshots(),
+	})
+}
+
+// checkUpstreams inspects every upstream. With connect set, idle
+// connections are woken up and given until the readiness timeout to become
+// READY.
+func (h *Handler) checkUpstreams(ctx context.Context, connect bool) ([]UpstreamStatus, bool) {
+	ctx, cancel := context.WithTimeout(ctx, h.health.timeout)
+	defer cancel()
+
+	ready := true
+	statuses := make([]UpstreamStatus, 0, len(h.upstreams))
+	for _, u := range h.upstreams {
+		s := UpstreamStatus{Service: u.service, Target: u.target}
+		if connect {
+			s.Ready = waitReady(ctx, u.conn)
+		} else {
+			s.Ready = u.conn.GetState() == connectivity.Ready
+		}
+		s.State = u.conn.GetState().String()
+
+		if s.Ready && h.health.checkUpstream {
+			resp, err := checkHealth(ctx, u)
+			if err != nil {
+				s.Ready = false
+				s.Error = err.Error()
+			} else {
+				s.Health = resp.GetStatus().String()
+				s.Ready = resp.GetStatus() == healthpb.HealthCheckResponse_SERVING
+			}
+		}
+
+		ready = ready && s.Ready
+		statuses = append(statuses, s)
+	}
+	return statuses, ready
+}
+
+// checkHealth runs the grpc.health.v1 check for the service, falling back to
+// the server-wide status when the service is not registered.
+func checkHealth(ctx context.Context, u upstream) (*healthpb.HealthCheckResponse, error) {
+	client := healthpb.NewHealthClient(u.conn)
+	resp, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: u.service})
+	if status.Code(err) == codes.NotFound {
+		return client.Check(ctx, &healthpb.HealthCheckRequest{})
+	}
+	return resp, err
+}
+
+// waitReady waits for conn to reach READY, connecting it if idle.
+func waitReady(ctx context.Context, conn *grpc.ClientConn) bool {
+	for {
+		state := conn.GetState()
+		switch state {
+		case connectivity.Ready:
+			return true
+		case connectivity.Idle:
+			conn.Connect()
+		case connectivity.Shutdown:
+			return false
+		}
+		if !conn.WaitForStateChange(ctx, state) {
+			return false
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/Forum-service/Forum-api-gateway/api/response"
 	"github.com/Forum-service/Forum-api-gateway/genproto/tag"
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog/log"
@@ -17,25 +18,24 @@ import (
 // @Produce json
 // @Security BearerAuth
 // @Param tag body tag.CreateTagRequest true "Tag information"
+// @Param Idempotency-Key header string false "Makes retries of the request safe, the first response is replayed"
 // @Success 201 {object} tag.Tag
-// @Failure 400 {object} string "Invalid request body"
-// @Failure 500 {object} string "Internal server error"
+// @Failure 400 {object} response.ErrorResponse "Invalid request body"
+// @Failure 409 {object} response.ErrorResponse "Idempotency-Key already used for a different request"
+// @Failure 425 {object} response.ErrorResponse "Request with the same Idempotency-Key still in progress"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
 // @Router /tags [post]
 func (h *Handler) CreateTag(c *gin.Context) {
 	var req tag.CreateTagRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		log.Error().Err(err).Msg("failed to bind json")
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": err.Error(),
-		})
+		log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to bind json")
+		response.AbortWithError(c, http.StatusBadRequest, err.Error())
 		return
 	}
 	resp, err := h.TagService.CreateTag(c.Request.Context(), &req)
 	if err != nil {
-		log.Error().Err(err).Msg("failed to create tag")
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": err.Error(),
-		})
+		log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to create tag")
+		response.AbortWithGRPCError(c, err)
 		return
 	}
 	c.JSON(http.StatusCreated, resp)
@@ -49,10 +49,13 @@ func (h *Handler) CreateTag(c *gin.Context) {
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "Tag ID"
+// @Param If-None-Match header string false "ETag of a cached copy; 304 if it is still current"
 // @Success 200 {object} tag.Tag
-// @Failure 400 {object} string "Invalid request body"
-// @Failure 404 {object} string "Tag item not found"
-// @Failure 500 {object} string "Internal server error"
+// @Header 200 {string} ETag "Version of the tag, send it as If-Match to update it"
+// @Success 304 "Not modified"
+// @Failure 400 {object} response.ErrorResponse "Invalid request body"
+// @Failure 404 {object} response.ErrorResponse "Tag item not found"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
 // @Router /tags/{id} [get]
 func (h *Handler) GetTagById(c *gin.Context) {
 	id := c.Param("id")
@@ -61,13 +64,11 @@ func (h *Handler) GetTagById(c *gin.Context) {
 		Id: id,
 	})
 	if err != nil {
-		log.Error().Err(err).Msg("failed to get tag")
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": err.Error(),
-		})
+		log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to get tag")
+		response.AbortWithGRPCError(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, resp)
+	writeVersioned(c, resp, resp.GetTag().GetUpdatedAt())
 }
 
 // UpdateTag godoc
@@ -79,30 +80,75 @@ func (h *Handler) GetTagById(c *gin.Context) {
 // @Security BearerAuth
 // @Param id path string true "Tag ID"
 // @Param tag body tag.UpdateTagRequest true "Tag information"
+// @Param If-Match header string false "ETag of the version being updated; the update fails with 412 if the tag changed since"
 // @Success 200 {object} tag.Tag
-// @Failure 400 {object} string "Invalid request body"
-// @Failure 404 {object} string "Tag item not found"
-// @Failure 500 {object} string "Internal server error"
+// @Header 200 {string} ETag "New version of the tag"
+// @Failure 400 {object} response.ErrorResponse "Invalid request body or If-Match header"
+// @Failure 404 {object} response.ErrorResponse "Tag item not found"
+// @Failure 412 {object} response.ErrorResponse "The tag was modified since the If-Match version"
+// @Failure 428 {object} response.ErrorResponse "If-Match is required"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
 // @Router /tags/{id} [put]
 func (h *Handler) UpdateTag(c *gin.Context) {
 	var req tag.UpdateTagRequest
-	id := c.Param("id")
-	req.Id = id
 	if err := c.ShouldBindJSON(&req); err != nil {
-		log.Error().Err(err).Msg("failed to bind json")
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": err.Error(),
-		})
+		log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to bind json")
+		response.AbortWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	// Set after binding so an id in the body cannot redirect the update
+	// away from the resource authorized by path.
+	req.Id = c.Param("id")
+	if !h.bindIfMatch(c, &req.ExpectedUpdatedAt) {
+		return
+	}
+	resp, err := h.TagService.UpdateTag(c.Request.Context(), &req)
+	if err != nil {
+		log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to update tag")
+		abortWithUpdateError(c, err, req.ExpectedUpdatedAt)
+		return
+	}
+	setVersion(c, resp.GetTag().GetUpdatedAt())
+	c.JSON(http.StatusOK, resp)
+}
+
+// PatchTag godoc
+// @Summary Partially update a tag
+// @Description Change only the fields present in a JSON Merge Patch (RFC 7396): name. A field set to null is cleared.
+// @Tags tag
+// @Accept json,application/merge-patch+json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Tag ID"
+// @Param patch body object true "Fields to change: name"
+// @Param If-Match header string false "ETag of the version being updated; the update fails with 412 if the tag changed since"
+// @Success 200 {object} tag.Tag
+// @Header 200 {string} ETag "New version of the tag"
+// @Failure 400 {object} response.ErrorResponse "Invalid patch or If-Match header"
+// @Failure 404 {object} response.ErrorResponse "Tag item not found"
+// @Failure 412 {object} response.ErrorResponse "The tag was modified since the If-Match version"
+// @Failure 415 {object} response.ErrorResponse "Unsupported Content-Type"
+// @Failure 428 {object} response.ErrorResponse "If-Match is required"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /tags/{id} [patch]
+func (h *Handler) PatchTag(c *gin.Context) {
+	var req tag.UpdateTagRequest
+	mask, ok := bindMergePatch(c, &req, tagPatchFields)
+	if !ok {
+		return
+	}
+	req.Id = c.Param("id")
+	req.UpdateMask = mask
+	if !h.bindIfMatch(c, &req.ExpectedUpdatedAt) {
 		return
 	}
 	resp, err := h.TagService.UpdateTag(c.Request.Context(), &req)
 	if err != nil {
-		log.Error().Err(err).Msg("failed to update tag")
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": err.Error(),
-		})
+		log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to patch tag")
+		abortWithUpdateError(c, err, req.ExpectedUpdatedAt)
 		return
 	}
+	setVersion(c, resp.GetTag().GetUpdatedAt())
 	c.JSON(http.StatusOK, resp)
 }
 
@@ -115,18 +161,16 @@ func (h *Handler) UpdateTag(c *gin.Context) {
 // @Security BearerAuth
 // @Param id path string true "Tag ID"
 // @Success 200 {object} tag.DeleteTagResponse
-// @Failure 400 {object} string "Invalid request body"
-// @Failure 404 {object} string "Tag item not found"
-// @Failure 500 {object} string "Internal server error"
+// @Failure 400 {object} response.ErrorResponse "Invalid request body"
+// @Failure 404 {object} response.ErrorResponse "Tag item not found"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
 // @Router /tags/{id} [delete]
 func (h *Handler) DeleteTag(c *gin.Context) {
 	id := c.Param("id")
 	resp, err := h.TagService.DeleteTag(c.Request.Context(), &tag.DeleteTagRequest{Id: id})
 	if err != nil {
-		log.Error().Err(err).Msg("failed to delete tag")
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": err.Error(),
-		})
+		log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to delete tag")
+		response.AbortWithGRPCError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, resp)
@@ -142,8 +186,12 @@ func (h *Handler) DeleteTag(c *gin.Context) {
 // @Param name query string false "Find by tags name"
 // @Param page query int false "Page number"
 // @Param limit query int false "Number of tags per page"
-// @Success 200 {object} tag.GetAllTagsResponse
-// @Failure 500 {object} string"Internal server error"
+// @Param sort query string false "Comma separated sort fields, prefix with - for descending: name, created_at, updated_at"
+// @Param include_deleted query bool false "Include soft deleted items (admins only)"
+// @Success 200 {object} response.Page{data=[]tag.Tag}
+// @Failure 400 {object} response.ErrorResponse "Invalid query parameters"
+// @Failure 403 {object} response.ErrorResponse "include_deleted requires an admin"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
 // @Router /tags [get]
 func (h *Handler) GetAllTags(c *gin.Context) {
 	var (
@@ -152,23 +200,28 @@ func (h *Handler) GetAllTags(c *gin.Context) {
 	)
 
 	req.Name = c.Query("name")
-	req.Page, req.Limit, err = ReadPageLimit(c)
+	req.Sort, err = readSort(c, tagSortFields)
 	if err != nil {
-		log.Error().Err(err).Msg("failed to read page limit")
-		c.JSON(http.StatusBadGateway, gin.H{
-			"error": err.Error(),
-		})
+		log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to read sort")
+		response.AbortWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !h.bindIncludeDeleted(c, &req.IncludeDeleted) {
+		return
+	}
+	req.Page, req.Limit, err = h.ReadPageLimit(c)
+	if err != nil {
+		log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to read page limit")
+		response.AbortWithError(c, http.StatusBadRequest, err.Error())
 		return
 	}
 	resp, err := h.TagService.GetAllTags(c.Request.Context(), &req)
 	if err != nil {
-		log.Error().Err(err).Msg("failed to get tags")
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": err.Error(),
-		})
+		log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to get tags")
+		response.AbortWithGRPCError(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, resp)
+	writePage(c, resp.GetTags(), req.Page, req.Limit, resp.GetTotal())
 }
 
 // GetFamousTags godoc
@@ -182,8 +235,9 @@ func (h *Handler) GetAllTags(c *gin.Context) {
 // @Param desc query bool false "Sort by count in descending order"
 // @Param page query int false "Page number"
 // @Param limit query int false "Number of tags per page"
-// @Success 200 {object} tag.GetFamousTagsRes
-// @Failure 500 {object} string "Internal server error"
+// @Success 200 {object} response.Page{data=[]tag.FamousTag}
+// @Failure 400 {object} response.ErrorResponse "Invalid query parameters"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
 // @Router /tags/popular [get]
 func (h *Handler) GetFamousTags(c *gin.Context) {
 	var (
@@ -194,30 +248,24 @@ func (h *Handler) GetFamousTags(c *gin.Context) {
 	req.Name = c.Query("name")
 	req.Desc, err = strconv.ParseBool(c.Query("desc"))
 	if err != nil {
-		log.Error().Err(err).Msg("failed to parse desc parameter")
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid desc parameter",
-		})
+		log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to parse desc parameter")
+		response.AbortWithError(c, http.StatusBadRequest, "Invalid desc parameter")
 		return
 	}
 
-	req.Page, req.Limit, err = ReadPageLimit(c)
+	req.Page, req.Limit, err = h.ReadPageLimit(c)
 	if err != nil {
-		log.Error().Err(err).Msg("failed to read page limit")
-		c.JSON(http.StatusBadGateway, gin.H{
-			"error": err.Error(),
-		})
+		log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to read page limit")
+		response.AbortWithError(c, http.StatusBadRequest, err.Error())
 		return
 	}
 
 	resp, err := h.TagService.GetFamousTags(c.Request.Context(), &req)
 	if err != nil {
-		log.Error().Err(err).Msg("failed to get famous tags")
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": err.Error(),
-		})
+		log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to get famous tags")
+		response.AbortWithGRPCError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, resp)
+	writePage(c, resp.GetTags(), req.Page, req.Limit, resp.GetTotal())
 }
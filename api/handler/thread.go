@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/Forum-service/Forum-api-gateway/genproto/comment"
+	"github.com/gin-gonic/gin"
+)
+
+// CommentNode is a comment together with its loaded replies. Replies below
+// the requested depth are not loaded; reply_count tells whether there are
+// any.
+type CommentNode struct {
+	*comment.Comment
+	Replies []*CommentNode `json:"replies,omitempty"`
+}
+
+// threadConfig bounds how deep comment trees are loaded.
+type threadConfig struct {
+	defaultDepth int
+	maxDepth     int
+}
+
+func newThreadConfig(def, max int) threadConfig {
+	if max < 0 {
+		max = 0
+	}
+	if def < 0 || def > max {
+		def = max
+	}
+	return threadConfig{defaultDepth: def, maxDepth: max}
+}
+
+// readDepth reads the depth query parameter, the number of reply levels
+// loaded below the top level comments.
+func (h *Handler) readDepth(c *gin.Context) (int, error) {
+	s := c.Query("depth")
+	if s == "" {
+		return h.threads.defaultDepth, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 || n > h.threads.maxDepth {
+		return 0, fmt.Errorf("invalid depth %q: must be between 0 and %d", s, h.threads.maxDepth)
+	}
+	return n, nil
+}
+
+// commentTree loads depth levels of replies below roots, one upstream call
+// per level. Each level is capped at the maximum page size, oldest replies
+// first; clients fetch the rest through /comments/{id}/replies.
+func (h *Handler) commentTree(ctx context.Context, postID string, roots []*comment.Comment, depth int) ([]*CommentNode, error) {
+	nodes := make([]*CommentNode, 0, len(roots))
+	byID := make(map[string]*CommentNode, len(roots))
+	for _, r := range roots {
+		n := &CommentNode{Comment: r}
+		nodes = append(nodes, n)
+		byID[r.GetId()] = n
+	}
+
+	level := nodes
+	for d := 0; d < depth && len(level) > 0; d++ {
+		ids := make([]string, 0, len(level))
+		for _, n := range level {
+			ids = append(ids, n.GetId())
+		}
+		resp, err := h.CommentService.GetAllComments(ctx, &comment.GetAllCommentsRequest{
+			PostId:    postID,
+			ParentIds: ids,
+			Sort:      []string{"created_at"},
+			Page:      1,
+			Limit:     h.pagination.maxLimit,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		var next []*CommentNode
+		for _, r := range resp.GetComments() {
+			parent, ok := byID[r.GetParentId()]
+			if !ok {
+				continue
+			}
+			n := &CommentNode{Comment: r}
+			parent.Replies = append(parent.Replies, n)
+			byID[r.GetId()] = n
+			next = append(next, n)
+		}
+		level = next
+	}
+
+	return nodes, nil
+}
@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/Forum-service/Forum-api-gateway/genproto/comment"
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+)
+
+// thread is a comment service holding the comments c1 <- c2 <- c3, each
+// replying to the one before.
+type thread struct {
+	comment.CommentServiceClient
+}
+
+func (thread) GetAllComments(_ context.Context, req *comment.GetAllCommentsRequest, _ ...grpc.CallOption) (*comment.GetAllCommentsResponse, error) {
+	all := []*comment.Comment{
+		{Id: "c1", PostId: "p1"},
+		{Id: "c2", PostId: "p1", ParentId: "c1"},
+		{Id: "c3", PostId: "p1", ParentId: "c2"},
+	}
+	var resp comment.GetAllCommentsResponse
+	for _, c := range all {
+		switch {
+		case req.TopLevelOnly && c.ParentId != "":
+		case len(req.ParentIds) > 0 && !contains(req.ParentIds, c.ParentId):
+		default:
+			resp.Comments = append(resp.Comments, c)
+		}
+	}
+	resp.Total = int64(len(resp.Comments))
+	return &resp, nil
+}
+
+func TestPostCommentsShapes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := &Handler{
+		CommentService: thread{},
+		pagination:     newPagination(10, 100),
+		threads:        newThreadConfig(1, 5),
+	}
+	r := gin.New()
+	r.GET("/posts/:id/comments", h.GetPostComments)
+	r.GET("/posts/:id/comments/tree", h.GetPostCommentTree)
+
+	type node struct {
+		ID      string `json:"id"`
+		Replies []node `json:"replies"`
+	}
+	read := func(target string) []node {
+		t.Helper()
+		w := get(t, r, target)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: status %d, body %s", target, w.Code, w.Body)
+		}
+		var page struct {
+			Data []node `json:"data"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+			t.Fatal(err)
+		}
+		return page.Data
+	}
+
+	flat := read("/posts/p1/comments")
+	if len(flat) != 3 {
+		t.Errorf("flat list has %d comments, want 3", len(flat))
+	}
+	for _, n := range flat {
+		if n.Replies != nil {
+			t.Errorf("flat comment %s has replies", n.ID)
+		}
+	}
+
+	tree := read("/posts/p1/comments/tree")
+	if len(tree) != 1 || len(tree[0].Replies) != 1 || tree[0].Replies[0].Replies != nil {
+		t.Errorf("tree with the default depth 1 = %+v, want c1 with reply c2 only", tree)
+	}
+	tree = read("/posts/p1/comments/tree?depth=2")
+	if len(tree) != 1 || len(tree[0].Replies) != 1 || len(tree[0].Replies[0].Replies) != 1 {
+		t.Errorf("tree with depth 2 = %+v, want c1 <- c2 <- c3", tree)
+	}
+
+	if w := get(t, r, "/posts/p1/comments/tree?depth=6"); w.Code != http.StatusBadRequest {
+		t.Errorf("depth above the maximum: status %d, want 400", w.Code)
+	}
+}
@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Forum-service/Forum-api-gateway/api/cache"
+	"github.com/Forum-service/Forum-api-gateway/api/response"
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// versionTag returns the entity tag of the resource version last updated
+// at updatedAt. The tag encodes updated_at so an If-Match can be sent
+// upstream as expected_updated_at.
+func versionTag(updatedAt string) string {
+	if updatedAt == "" {
+		return ""
+	}
+	return `"` + base64.RawURLEncoding.EncodeToString([]byte(updatedAt)) + `"`
+}
+
+// parseVersionTag returns the updated_at encoded in a strong entity tag.
+func parseVersionTag(tag string) (string, bool) {
+	if len(tag) < 3 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		return "", false
+	}
+	b, err := base64.RawURLEncoding.DecodeString(tag[1 : len(tag)-1])
+	if err != nil || len(b) == 0 {
+		return "", false
+	}
+	return string(b), true
+}
+
+// setVersion sets the ETag header to the version last updated at updatedAt.
+func setVersion(c *gin.Context, updatedAt string) {
+	if tag := versionTag(updatedAt); tag != "" {
+		c.Header("ETag", tag)
+	}
+}
+
+// writeVersioned responds with obj and its version as ETag, or with 304
+// when If-None-Match names that version.
+func writeVersioned(c *gin.Context, obj interface{}, updatedAt string) {
+	setVersion(c, updatedAt)
+	if tag := versionTag(updatedAt); tag != "" && cache.MatchesETag(c.GetHeader("If-None-Match"), tag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.JSON(http.StatusOK, obj)
+}
+
+// bindIfMatch sets *expected, the expected_updated_at of an update, from
+// the If-Match header, which holds "*" or a list of ETags returned by the
+// gateway. If-Match uses strong comparison, so weak tags never match. Of
+// several tags only the newest version can still be current, so that one is
+// sent upstream. A header without a usable tag cannot match the current
+// version and gets 412, a malformed one 400; a missing header gets 428 when
+// If-Match is required and the body does not set expected_updated_at
+// either. It aborts the request and returns false on failure.
+func (h *Handler) bindIfMatch(c *gin.Context, expected *string) bool {
+	s := strings.TrimSpace(c.GetHeader("If-Match"))
+	switch s {
+	case "":
+		if *expected == "" && h.requireIfMatch {
+			response.AbortWithError(c, http.StatusPreconditionRequired, "If-Match is required, send the ETag of the resource being updated")
+			return false
+		}
+		return true
+	case "*":
+		return true
+	}
+	tags, ok := parseETags(s)
+	if !ok {
+		response.AbortWithError(c, http.StatusBadRequest, `Invalid If-Match header, send "*" or a comma separated list of ETags`)
+		return false
+	}
+
+	var newest string
+	for _, tag := range tags {
+		if strings.HasPrefix(tag, "W/") {
+			continue
+		}
+		if updatedAt, ok := parseVersionTag(tag); ok && (newest == "" || newerVersion(updatedAt, newest)) {
+			newest = updatedAt
+		}
+	}
+	if newest == "" {
+		response.AbortWithError(c, http.StatusPreconditionFailed, "If-Match does not match the current version of the resource")
+		return false
+	}
+	*expected = newest
+	return true
+}
+
+// parseETags splits a list of entity tags as sent in If-Match. It reports
+// false if s is not a comma separated list of strong or weak (W/) quoted
+// tags as defined by RFC 9110. Tags may contain commas.
+func parseETags(s string) ([]string, bool) {
+	var tags []string
+	for {
+		s = strings.TrimLeft(s, " \t")
+		if s == "" {
+			return tags, len(tags) > 0
+		}
+		if s[0] == ',' {
+			s = s[1:]
+			continue
+		}
+
+		opaque := strings.TrimPrefix(s, "W/")
+		if opaque == "" || opaque[0] != '"' {
+			return nil, false
+		}
+		end := strings.IndexByte(opaque[1:], '"')
+		if end < 0 {
+			return nil, false
+		}
+		for _, b := range []byte(opaque[1 : end+1]) {
+			if b <= ' ' || b == 0x7f {
+				return nil, false
+			}
+		}
+		n := len(s) - len(opaque) + end + 2
+		tags = append(tags, s[:n])
+
+		s = strings.TrimLeft(s[n:], " \t")
+		if s != "" && s[0] != ',' {
+			return nil, false
+		}
+	}
+}
+
+// newerVersion reports whether updated_at a is later than b. Values that
+// are not RFC 3339 timestamps are compared as strings.
+func newerVersion(a, b string) bool {
+	ta, errA := time.Parse(time.RFC3339Nano, a)
+	tb, errB := time.Parse(time.RFC3339Nano, b)
+	if errA != nil || errB != nil {
+		return a > b
+	}
+	return ta.After(tb)
+}
+
+// abortWithUpdateError aborts a failed update. The upstream answers ABORTED
+// when expected_updated_at no longer matches, which is a 412 for clients.
+func abortWithUpdateError(c *gin.Context, err error, expected string) {
+	if expected != "" && status.Code(err) == codes.Aborted {
+		response.AbortWithError(c, http.StatusPreconditionFailed, "The resource was modified since it was read, fetch it again and retry")
+		return
+	}
+	response.AbortWithGRPCError(c, err)
+}
@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestBindIfMatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	const (
+		older = "2024-01-01T10:00:00Z"
+		newer = "2024-01-01T12:00:00Z"
+	)
+	v1, v2 := versionTag(older), versionTag(newer)
+
+	tests := []struct {
+		name     string
+		header   string
+		body     string
+		required bool
+		want     int
+		expected string
+	}{
+		{"no header", "", "", false, http.StatusOK, ""},
+		{"no header, required", "", "", true, http.StatusPreconditionRequired, ""},
+		{"no header, required, body version", "", older, true, http.StatusOK, older},
+		{"any", "*", "", true, http.StatusOK, ""},
+		{"strong tag", v1, "", false, http.StatusOK, older},
+		{"header over body", v2, older, false, http.StatusOK, newer},
+		{"list", v1 + ", " + v2, "", false, http.StatusOK, newer},
+		{"list without spaces", v2 + "," + v1, "", false, http.StatusOK, newer},
+		{"list with foreign tag", `"xyzzy", ` + v1, "", false, http.StatusOK, older},
+		{"list with weak tag", "W/" + v2 + ", " + v1, "", false, http.StatusOK, older},
+		{"empty list elements", " , " + v1 + " ,", "", false, http.StatusOK, older},
+		{"weak tag only", "W/" + v1, "", false, http.StatusPreconditionFailed, ""},
+		{"foreign tag", `"xyzzy"`, "", false, http.StatusPreconditionFailed, ""},
+		{"empty tag", `""`, "", false, http.StatusPreconditionFailed, ""},
+		{"tag with comma", `"a,b"`, "", false, http.StatusPreconditionFailed, ""},
+		{"unquoted", "abc", "", false, http.StatusBadRequest, ""},
+		{"unterminated", `"abc`, "", false, http.StatusBadRequest, ""},
+		{"missing comma", v1 + " " + v2, "", false, http.StatusBadRequest, ""},
+		{"space in tag", `"a b"`, "", false, http.StatusBadRequest, ""},
+		{"star in list", "*, " + v1, "", false, http.StatusBadRequest, ""},
+		{"only commas", ",,", "", false, http.StatusBadRequest, ""},
+	}
+	for _, tt := range tests {
+		h := &Handler{requireIfMatch: tt.required}
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPut, "/posts/1", nil)
+		if tt.header != "" {
+			c.Request.Header.Set("If-Match", tt.header)
+		}
+
+		expected := tt.body
+		ok := h.bindIfMatch(c, &expected)
+		got := http.StatusOK
+		if !ok {
+			got = w.Code
+		}
+		if got != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.name, got, tt.want)
+		}
+		if ok && expected != tt.expected {
+			t.Errorf("%s: expected_updated_at = %q, want %q", tt.name, expected, tt.expected)
+		}
+	}
+}
+
+func TestParseETags(t *testing.T) {
+	tags, ok := parseETags(`"a", W/"b" ,"c,d"`)
+	if want := []string{`"a"`, `W/"b"`, `"c,d"`}; !ok || !reflect.DeepEqual(tags, want) {
+		t.Errorf("parseETags = %q, %v, want %q", tags, ok, want)
+	}
+}
@@ -0,0 +1,88 @@
+package idempotency
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often expired keys are dropped from a MemoryStore.
+const sweepInterval = time.Minute
+
+type record struct {
+	hash    string
+	resp    *Response
+	expires time.Time
+}
+
+// MemoryStore is a Store local to one gateway process. Retries reaching
+// another replica are not deduplicated by the gateway.
+type MemoryStore struct {
+	mu        sync.Mutex
+	records   map[string]*record
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		records: map[string]*record{},
+		now:     time.Now,
+	}
+}
+
+// Begin implements Store.
+func (s *MemoryStore) Begin(_ context.Context, key, hash string, ttl time.Duration) (*Response, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if now.Sub(s.lastSweep) >= sweepInterval {
+		s.sweep(now)
+	}
+
+	if r, ok := s.records[key]; ok && now.Before(r.expires) {
+		switch {
+		case r.hash != hash:
+			return nil, ErrMismatch
+		case r.resp == nil:
+			return nil, ErrInFlight
+		default:
+			return r.resp, nil
+		}
+	}
+	s.records[key] = &record{hash: hash, expires: now.Add(ttl)}
+	return nil, nil
+}
+
+// Complete implements Store.
+func (s *MemoryStore) Complete(_ context.Context, key string, resp Response, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r, ok := s.records[key]; ok {
+		r.resp = &resp
+		r.expires = s.now().Add(ttl)
+	}
+	return nil
+}
+
+// Release implements Store.
+func (s *MemoryStore) Release(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.records, key)
+	return nil
+}
+
+// sweep drops expired records. It must be called with s.mu held.
+func (s *MemoryStore) sweep(now time.Time) {
+	for key, r := range s.records {
+		if !now.Before(r.expires) {
+			delete(s.records, key)
+		}
+	}
+	s.lastSweep = now
+}
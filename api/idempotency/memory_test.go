@@ -0,0 +1,102 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// newTestStore returns a store whose clock only moves through the returned
+// advance function.
+func newTestStore() (*MemoryStore, func(time.Duration)) {
+	s := NewMemoryStore()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+	return s, func(d time.Duration) { now = now.Add(d) }
+}
+
+func TestMemoryStoreBegin(t *testing.T) {
+	ctx := context.Background()
+	done := Response{Status: 201, Body: []byte("created")}
+	tests := []struct {
+		name     string
+		prepare  func(s *MemoryStore)
+		hash     string
+		wantResp bool
+		wantErr  error
+	}{
+		{"new key", func(*MemoryStore) {}, "h", false, nil},
+		{"in flight", func(s *MemoryStore) {
+			s.Begin(ctx, "k", "h", time.Hour)
+		}, "h", false, ErrInFlight},
+		{"completed", func(s *MemoryStore) {
+			s.Begin(ctx, "k", "h", time.Hour)
+			s.Complete(ctx, "k", done, time.Hour)
+		}, "h", true, nil},
+		{"different request while in flight", func(s *MemoryStore) {
+			s.Begin(ctx, "k", "h", time.Hour)
+		}, "other", false, ErrMismatch},
+		{"different request after completion", func(s *MemoryStore) {
+			s.Begin(ctx, "k", "h", time.Hour)
+			s.Complete(ctx, "k", done, time.Hour)
+		}, "other", false, ErrMismatch},
+		{"released", func(s *MemoryStore) {
+			s.Begin(ctx, "k", "h", time.Hour)
+			s.Release(ctx, "k")
+		}, "other", false, nil},
+	}
+	for _, tt := range tests {
+		s, _ := newTestStore()
+		tt.prepare(s)
+		resp, err := s.Begin(ctx, "k", tt.hash, time.Hour)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.wantErr)
+		}
+		if (resp != nil) != tt.wantResp {
+			t.Errorf("%s: response = %v, want stored response %v", tt.name, resp, tt.wantResp)
+		}
+		if resp != nil && (resp.Status != done.Status || string(resp.Body) != string(done.Body)) {
+			t.Errorf("%s: response = %+v, want %+v", tt.name, resp, done)
+		}
+	}
+}
+
+func TestMemoryStoreExpiresKeys(t *testing.T) {
+	ctx := context.Background()
+	s, advance := newTestStore()
+
+	s.Begin(ctx, "k", "h", time.Minute)
+	s.Complete(ctx, "k", Response{Status: 201}, time.Hour)
+	advance(time.Hour - time.Nanosecond)
+	if resp, err := s.Begin(ctx, "k", "h", time.Hour); resp == nil || err != nil {
+		t.Fatalf("Begin before expiry = %v, %v, want the stored response", resp, err)
+	}
+	advance(time.Nanosecond)
+	if resp, err := s.Begin(ctx, "k", "other", time.Hour); resp != nil || err != nil {
+		t.Fatalf("Begin after expiry = %v, %v, want a fresh reservation", resp, err)
+	}
+
+	// An abandoned reservation expires with the Begin TTL.
+	s.Begin(ctx, "abandoned", "h", time.Minute)
+	advance(time.Minute)
+	if _, err := s.Begin(ctx, "abandoned", "h", time.Minute); err != nil {
+		t.Errorf("Begin on an expired reservation: %v", err)
+	}
+}
+
+func TestMemoryStoreSweepsExpiredKeys(t *testing.T) {
+	ctx := context.Background()
+	s, advance := newTestStore()
+	s.Begin(ctx, "old", "h", time.Second)
+	s.Begin(ctx, "kept", "h", time.Hour)
+
+	advance(sweepInterval)
+	s.Begin(ctx, "new", "h", time.Hour)
+	if _, ok := s.records["old"]; ok {
+		t.Error("expired key was not swept")
+	}
+	if _, ok := s.records["kept"]; !ok {
+		t.Error("live key was swept")
+	}
+}
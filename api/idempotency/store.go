@@ -0,0 +1,39 @@
+// Package idempotency remembers the responses of requests carrying an
+// Idempotency-Key so retries get the original response instead of
+// repeating the side effect.
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+var (
+	// ErrMismatch is returned when a key is reused for a different request.
+	ErrMismatch = errors.New("idempotency key reused for a different request")
+	// ErrInFlight is returned while the request that first used a key is
+	// still being processed.
+	ErrInFlight = errors.New("idempotency key in use by a request in flight")
+)
+
+// Response is a stored response.
+type Response struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// Store keeps the state of idempotency keys.
+type Store interface {
+	// Begin reserves key for the request identified by hash for ttl. It
+	// returns the stored response if the request was already completed,
+	// nil if the caller now holds the key, ErrMismatch if the key was used
+	// with another hash and ErrInFlight if the request is still running.
+	Begin(ctx context.Context, key, hash string, ttl time.Duration) (*Response, error)
+	// Complete stores the response of the reserved key for ttl.
+	Complete(ctx context.Context, key string, resp Response, ttl time.Duration) error
+	// Release drops the reservation of key, so the request can be retried.
+	Release(ctx context.Context, key string) error
+}
@@ -0,0 +1,117 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "gateway"
+
+var (
+	// HTTPRequests counts handled requests by route template, method and status.
+	HTTPRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "HTTP requests handled, by route template, method and status code.",
+	}, []string{"route", "method", "status"})
+
+	// HTTPDuration observes request latency by route template, method and status.
+	HTTPDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "HTTP request latency, by route template, method and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method", "status"})
+
+	// HTTPInFlight is the number of requests being served.
+	HTTPInFlight = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_in_flight",
+		Help:      "HTTP requests currently being served, by route template.",
+	}, []string{"route"})
+
+	// GRPCRequests counts upstream calls by service, method and status code.
+	GRPCRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "grpc_client",
+		Name:      "requests_total",
+		Help:      "Upstream gRPC calls, by service, method and status code.",
+	}, []string{"service", "method", "code"})
+
+	// GRPCDuration observes upstream call latency by service, method and status code.
+	GRPCDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "grpc_client",
+		Name:      "request_duration_seconds",
+		Help:      "Upstream gRPC call latency including retries, by service, method and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"service", "method", "code"})
+
+	// GRPCInFlight is the number of upstream calls in progress.
+	GRPCInFlight = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "grpc_client",
+		Name:      "requests_in_flight",
+		Help:      "Upstream gRPC calls in progress, by service.",
+	}, []string{"service"})
+
+	// GRPCRetries counts retried upstream calls.
+	GRPCRetries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "grpc_client",
+		Name:      "retries_total",
+		Help:      "Upstream gRPC call retries, by service and method.",
+	}, []string{"service", "method"})
+
+	// BreakerState is 0 when closed, 1 when half-open and 2 when open.
+	BreakerState = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "circuit_breaker",
+		Name:      "state",
+		Help:      "Circuit breaker state per upstream service: 0 closed, 1 half-open, 2 open.",
+	}, []string{"service"})
+
+	// BreakerTransitions counts breaker state changes.
+	BreakerTransitions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "circuit_breaker",
+		Name:      "transitions_total",
+		Help:      "Circuit breaker state transitions, by service and target state.",
+	}, []string{"service", "state"})
+
+	// BreakerRejected counts calls rejected by an open breaker.
+	BreakerRejected = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "circuit_breaker",
+		Name:      "rejected_total",
+		Help:      "Upstream calls rejected while the circuit breaker was open, by service.",
+	}, []string{"service"})
+
+	// RateLimited counts requests rejected by the rate limiter.
+	RateLimited = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "ratelimit",
+		Name:      "rejected_total",
+		Help:      "HTTP requests rejected by the rate limiter, by route template and method.",
+	}, []string{"route", "method"})
+
+	// CacheRequests counts cacheable requests by route template and result.
+	CacheRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "cache",
+		Name:      "requests_total",
+		Help:      "Requests to cached routes, by route template and result (hit, miss or shared).",
+	}, []string{"route", "result"})
+
+	// IdempotencyRequests counts POST requests with an Idempotency-Key that
+	// were not processed normally.
+	IdempotencyRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "idempotency",
+		Name:      "requests_total",
+		Help:      "Requests with a reused Idempotency-Key, by route template and result (replayed, mismatch or in_flight).",
+	}, []string{"route", "result"})
+)
@@ -0,0 +1,207 @@
+package middlewares
+
+import (
+	"bytes"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Forum-service/Forum-api-gateway/api/cache"
+	"github.com/Forum-service/Forum-api-gateway/api/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+// cachedHeaders are the response headers stored with a cached body.
+var cachedHeaders = []string{"Content-Type", "Link"}
+
+// ResponseCache caches GET responses of selected routes and drops them
+// when a write to the same data succeeds. A nil ResponseCache disables
+// caching. The cache is local to the process, so other replicas keep
+// serving their entries until they expire.
+type ResponseCache struct {
+	store *cache.Cache
+	ttls  map[string]time.Duration
+}
+
+// NewResponseCache returns a ResponseCache using the TTLs keyed by route
+// template, e.g. /v1/posts/:id. Routes without a TTL are not cached.
+func NewResponseCache(store *cache.Cache, ttls map[string]time.Duration) *ResponseCache {
+	return &ResponseCache{store: store, ttls: ttls}
+}
+
+// Cached serves the route from the cache. Entries are keyed by path,
+// normalized query and the caller's roles and scopes, and belong to group,
+// which may reference path parameters, e.g. post:{id}. Concurrent misses
+// for the same key are collapsed into one upstream call. Responses carry an
+// ETag, the handler's own or a hash of the body, and a matching
+// If-None-Match gets 304.
+func (rc *ResponseCache) Cached(group string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if rc == nil || c.Request.Method != http.MethodGet {
+			c.Next()
+			return
+		}
+		route := c.FullPath()
+		ttl := rc.ttls[route]
+		if ttl <= 0 {
+			c.Next()
+			return
+		}
+
+		key := cacheKey(c)
+		if e, ok := rc.store.Get(key); ok {
+			metrics.CacheRequests.WithLabelValues(route, "hit").Inc()
+			serveEntry(c, e, "HIT")
+			return
+		}
+
+		leader := false
+		e, _, _ := rc.store.Do(key, func() (*cache.Entry, error) {
+			leader = true
+			epoch := rc.store.Epoch()
+			// The response is shared with other callers, so the handler
+			// must not answer this caller's conditional request.
+			ifNoneMatch := c.Request.Header.Values("If-None-Match")
+			c.Request.Header.Del("If-None-Match")
+			w := &bufferWriter{ResponseWriter: c.Writer, status: http.StatusOK}
+			c.Writer = w
+			defer func() {
+				c.Writer = w.ResponseWriter
+				if len(ifNoneMatch) > 0 {
+					c.Request.Header["If-None-Match"] = ifNoneMatch
+				}
+			}()
+			c.Next()
+
+			e := &cache.Entry{
+				Status:  w.status,
+				Header:  http.Header{},
+				Body:    w.body.Bytes(),
+				Expires: time.Now().Add(ttl),
+			}
+			for _, name := range cachedHeaders {
+				if v := w.Header().Values(name); len(v) > 0 {
+					e.Header[name] = v
+				}
+			}
+			if e.Status == http.StatusOK {
+				// Keep the version tag of handlers that set one.
+				if e.ETag = w.Header().Get("ETag"); e.ETag == "" {
+					e.ETag = cache.ETag(e.Body)
+				}
+				rc.store.Set(key, expandParams(c, group), epoch, e)
+			}
+			return e, nil
+		})
+
+		switch {
+		case leader:
+			metrics.CacheRequests.WithLabelValues(route, "miss").Inc()
+			serveEntry(c, e, "MISS")
+		case e != nil && e.Status == http.StatusOK:
+			metrics.CacheRequests.WithLabelValues(route, "shared").Inc()
+			serveEntry(c, e, "HIT")
+		default:
+			// The shared call failed; make the request on our own.
+			c.Next()
+		}
+	}
+}
+
+// Invalidates drops the given groups once the request succeeded. Groups
+// may reference path parameters like Cached.
+func (rc *ResponseCache) Invalidates(groups ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		if rc == nil {
+			return
+		}
+		if status := c.Writer.Status(); status < 200 || status >= 300 {
+			return
+		}
+		for _, group := range groups {
+			rc.store.Invalidate(expandParams(c, group))
+		}
+	}
+}
+
+// serveEntry writes e, or 304 when it matches If-None-Match.
+func serveEntry(c *gin.Context, e *cache.Entry, result string) {
+	c.Abort()
+	h := c.Writer.Header()
+	for name, v := range e.Header {
+		h[name] = v
+	}
+	if e.ETag != "" {
+		h.Set("ETag", e.ETag)
+		h.Set("Cache-Control", "private, no-cache")
+		h.Set("X-Cache", result)
+		if cache.MatchesETag(c.GetHeader("If-None-Match"), e.ETag) {
+			c.Writer.WriteHeader(http.StatusNotModified)
+			c.Writer.WriteHeaderNow()
+			return
+		}
+	}
+	c.Writer.WriteHeader(e.Status)
+	c.Writer.Write(e.Body)
+}
+
+// cacheKey identifies a response by path, normalized query and what the
+// caller may see. Callers with the same roles and scopes share entries.
+func cacheKey(c *gin.Context) string {
+	var perms []string
+	if principal, ok := GetPrincipal(c); ok {
+		perms = append(append(perms, principal.Roles...), principal.Scopes...)
+		sort.Strings(perms)
+	}
+	return c.Request.URL.Path + "?" + c.Request.URL.Query().Encode() + "|" + strings.Join(perms, " ")
+}
+
+// expandParams replaces {name} in s with the value of path parameter name.
+func expandParams(c *gin.Context, s string) string {
+	for {
+		start := strings.IndexByte(s, '{')
+		end := strings.IndexByte(s, '}')
+		if start < 0 || end < start {
+			return s
+		}
+		s = s[:start] + c.Param(s[start+1:end]) + s[end+1:]
+	}
+}
+
+// bufferWriter holds back the response so it can be cached and tagged
+// before it is sent.
+type bufferWriter struct {
+	gin.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *bufferWriter) WriteHeader(code int) {
+	if code > 0 {
+		w.status = code
+	}
+}
+
+func (w *bufferWriter) WriteHeaderNow() {}
+
+func (w *bufferWriter) Write(b []byte) (int, error) {
+	return w.body.Write(b)
+}
+
+func (w *bufferWriter) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
+
+func (w *bufferWriter) Status() int {
+	return w.status
+}
+
+func (w *bufferWriter) Size() int {
+	return w.body.Len()
+}
+
+func (w *bufferWriter) Written() bool {
+	return w.body.Len() > 0
+}
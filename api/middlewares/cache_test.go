@@ -0,0 +1,194 @@
+package middlewares
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Forum-service/Forum-api-gateway/api/authz"
+	"github.com/Forum-service/Forum-api-gateway/api/cache"
+	"github.com/gin-gonic/gin"
+)
+
+// cachedPosts is a router caching GET /posts/:id. The X-Roles request
+// header sets the caller's roles; X-Status makes the handler fail.
+type cachedPosts struct {
+	*gin.Engine
+	calls   atomic.Int32
+	release chan struct{}
+}
+
+func newCachedPosts() *cachedPosts {
+	gin.SetMode(gin.TestMode)
+	rc := NewResponseCache(cache.New(100), map[string]time.Duration{"/posts/:id": time.Minute})
+	r := &cachedPosts{Engine: gin.New()}
+	r.Use(func(c *gin.Context) {
+		if roles := c.GetHeader("X-Roles"); roles != "" {
+			c.Set(PrincipalKey, authz.Principal{UserID: "u", Roles: strings.Split(roles, ",")})
+		}
+	})
+	r.GET("/posts/:id", rc.Cached("post:{id}"), func(c *gin.Context) {
+		n := r.calls.Add(1)
+		if r.release != nil {
+			<-r.release
+		}
+		if s := c.GetHeader("X-Status"); s != "" {
+			c.String(http.StatusServiceUnavailable, "unavailable")
+			return
+		}
+		c.String(http.StatusOK, fmt.Sprintf("post %s for %s, render %d", c.Param("id"), c.GetHeader("X-Roles"), n))
+	})
+	r.PUT("/posts/:id", rc.Invalidates("post:{id}"), func(c *gin.Context) {
+		if s := c.GetHeader("X-Status"); s != "" {
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	})
+	return r
+}
+
+func (r *cachedPosts) do(method, target string, header ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, nil)
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestCachedServesHits(t *testing.T) {
+	r := newCachedPosts()
+	first := r.do("GET", "/posts/1?b=2&a=1")
+	second := r.do("GET", "/posts/1?a=1&b=2")
+
+	if got := first.Header().Get("X-Cache"); got != "MISS" {
+		t.Errorf("first X-Cache = %q, want MISS", got)
+	}
+	if got := second.Header().Get("X-Cache"); got != "HIT" {
+		t.Errorf("second X-Cache = %q, want HIT", got)
+	}
+	if first.Body.String() != second.Body.String() || r.calls.Load() != 1 {
+		t.Errorf("handler ran %d times, bodies %q and %q", r.calls.Load(), first.Body, second.Body)
+	}
+	if r.do("GET", "/posts/2"); r.calls.Load() != 2 {
+		t.Error("another path was served from the cache")
+	}
+}
+
+func TestCachedSeparatesAuthScopes(t *testing.T) {
+	r := newCachedPosts()
+	anonymous := r.do("GET", "/posts/1")
+	moderator := r.do("GET", "/posts/1", "X-Roles", "forum:moderator,forum:user")
+	reordered := r.do("GET", "/posts/1", "X-Roles", "forum:user,forum:moderator")
+	user := r.do("GET", "/posts/1", "X-Roles", "forum:user")
+
+	if n := r.calls.Load(); n != 3 {
+		t.Errorf("handler calls = %d, want 3, one per distinct role set", n)
+	}
+	if anonymous.Body.String() == moderator.Body.String() || moderator.Body.String() == user.Body.String() {
+		t.Error("callers with different roles shared a cached response")
+	}
+	if got := reordered.Header().Get("X-Cache"); got != "HIT" {
+		t.Errorf("same roles in another order: X-Cache = %q, want HIT", got)
+	}
+}
+
+func TestCachedAnswersIfNoneMatch(t *testing.T) {
+	r := newCachedPosts()
+	first := r.do("GET", "/posts/1")
+	etag := first.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("response has no ETag")
+	}
+
+	w := r.do("GET", "/posts/1", "If-None-Match", etag)
+	if w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Errorf("matching If-None-Match: status %d, body %q, want 304 without body", w.Code, w.Body)
+	}
+	if got := w.Header().Get("ETag"); got != etag {
+		t.Errorf("304 ETag = %q, want %q", got, etag)
+	}
+	if w := r.do("GET", "/posts/1", "If-None-Match", `"stale"`); w.Code != http.StatusOK {
+		t.Errorf("stale If-None-Match: status %d, want 200", w.Code)
+	}
+	// A conditional miss must still fill the cache with the full body.
+	if w := r.do("GET", "/posts/2", "If-None-Match", etag); w.Code != http.StatusOK || w.Body.Len() == 0 {
+		t.Errorf("conditional miss: status %d, body %q", w.Code, w.Body)
+	}
+	if w := r.do("GET", "/posts/2"); w.Body.Len() == 0 || w.Header().Get("X-Cache") != "HIT" {
+		t.Errorf("entry filled by a conditional miss: X-Cache %q, body %q", w.Header().Get("X-Cache"), w.Body)
+	}
+}
+
+func TestCachedInvalidatesOnSuccessfulWrites(t *testing.T) {
+	r := newCachedPosts()
+	r.do("GET", "/posts/1")
+	r.do("GET", "/posts/2")
+
+	r.do("PUT", "/posts/1", "X-Status", "fail")
+	if w := r.do("GET", "/posts/1"); w.Header().Get("X-Cache") != "HIT" {
+		t.Error("failed write invalidated the cache")
+	}
+	r.do("PUT", "/posts/1")
+	if w := r.do("GET", "/posts/1"); w.Header().Get("X-Cache") != "MISS" {
+		t.Error("successful write did not invalidate its group")
+	}
+	if w := r.do("GET", "/posts/2"); w.Header().Get("X-Cache") != "HIT" {
+		t.Error("write to post 1 invalidated post 2")
+	}
+}
+
+func TestCachedDoesNotStoreErrors(t *testing.T) {
+	r := newCachedPosts()
+	if w := r.do("GET", "/posts/1", "X-Status", "fail"); w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status %d, want 503", w.Code)
+	}
+	if w := r.do("GET", "/posts/1"); w.Code != http.StatusOK || r.calls.Load() != 2 {
+		t.Errorf("after an error: status %d with %d handler calls, want a fresh 200", w.Code, r.calls.Load())
+	}
+}
+
+func TestCachedCollapsesConcurrentMisses(t *testing.T) {
+	r := newCachedPosts()
+	r.release = make(chan struct{})
+
+	const n = 10
+	var (
+		wg      sync.WaitGroup
+		started sync.WaitGroup
+		bodies  = make([]string, n)
+	)
+	started.Add(n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			started.Done()
+			w := r.do("GET", "/posts/1")
+			if w.Code != http.StatusOK {
+				t.Errorf("status %d", w.Code)
+			}
+			bodies[i] = w.Body.String()
+		}(i)
+	}
+	started.Wait()
+	time.Sleep(20 * time.Millisecond)
+	close(r.release)
+	wg.Wait()
+
+	if got := r.calls.Load(); got != 1 {
+		t.Errorf("handler calls = %d, want 1", got)
+	}
+	for _, b := range bodies {
+		if b != bodies[0] {
+			t.Errorf("bodies differ: %q and %q", b, bodies[0])
+		}
+	}
+}
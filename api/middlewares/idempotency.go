@@ -0,0 +1,134 @@
+package middlewares
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Forum-service/Forum-api-gateway/api/idempotency"
+	"github.com/Forum-service/Forum-api-gateway/api/metrics"
+	"github.com/Forum-service/Forum-api-gateway/api/response"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+const maxIdempotencyKeyLength = 255
+
+// replayedHeaders are the response headers stored with a response and
+// sent again on replay.
+var replayedHeaders = []string{"Content-Type", "Location", "Link"}
+
+// Idempotency makes POST requests carrying an Idempotency-Key header safe to
+// retry. The first successful response is stored for ttl under the caller,
+// the key and a hash of the request, and replayed with an
+// Idempotent-Replayed header on retries. Reusing a key for a different
+// request gets 409, retrying while the first request is still running gets
+// 425. Failed requests are not stored so they can be retried. Callers are
+// identified like in RateLimit, so the middleware runs after Auth.
+func Idempotency(store idempotency.Store, ttl time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader("Idempotency-Key")
+		if key == "" || c.Request.Method != http.MethodPost {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			response.AbortWithError(c, http.StatusBadRequest, "Idempotency-Key must be at most 255 characters")
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			response.AbortWithError(c, http.StatusBadRequest, "Failed to read request body")
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		route := c.FullPath()
+		ctx := context.WithoutCancel(c.Request.Context())
+		key = clientKey(c, "") + "|" + key
+		stored, err := store.Begin(ctx, key, requestHash(c, body), ttl)
+		switch {
+		case errors.Is(err, idempotency.ErrMismatch):
+			metrics.IdempotencyRequests.WithLabelValues(route, "mismatch").Inc()
+			response.AbortWithError(c, http.StatusConflict, "Idempotency-Key was already used for a different request")
+			return
+		case errors.Is(err, idempotency.ErrInFlight):
+			metrics.IdempotencyRequests.WithLabelValues(route, "in_flight").Inc()
+			c.Header("Retry-After", "1")
+			response.AbortWithError(c, http.StatusTooEarly, "A request with this Idempotency-Key is still being processed")
+			return
+		case err != nil:
+			log.Ctx(c.Request.Context()).Warn().Err(err).Msg("idempotency store failed, processing request without it")
+			c.Next()
+			return
+		case stored != nil:
+			metrics.IdempotencyRequests.WithLabelValues(route, "replayed").Inc()
+			c.Abort()
+			for name, v := range stored.Header {
+				c.Writer.Header()[name] = v
+			}
+			c.Header("Idempotent-Replayed", "true")
+			c.Writer.WriteHeader(stored.Status)
+			c.Writer.Write(stored.Body)
+			return
+		}
+
+		w := &recordWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		completed := false
+		defer func() {
+			c.Writer = w.ResponseWriter
+			if !completed {
+				if err := store.Release(ctx, key); err != nil {
+					log.Ctx(ctx).Warn().Err(err).Msg("failed to release idempotency key")
+				}
+			}
+		}()
+		c.Next()
+
+		if status := w.Status(); status >= 200 && status < 300 {
+			resp := idempotency.Response{Status: status, Header: http.Header{}, Body: w.body.Bytes()}
+			for _, name := range replayedHeaders {
+				if v := w.Header().Values(name); len(v) > 0 {
+					resp.Header[name] = v
+				}
+			}
+			if err := store.Complete(ctx, key, resp, ttl); err != nil {
+				log.Ctx(ctx).Warn().Err(err).Msg("failed to store idempotent response")
+				return
+			}
+			completed = true
+		}
+	}
+}
+
+// requestHash identifies a request by method, path and body. Bodies must
+// match byte for byte.
+func requestHash(c *gin.Context, body []byte) string {
+	h := sha256.New()
+	io.WriteString(h, c.Request.Method+" "+c.Request.URL.RequestURI()+"\n")
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// recordWriter sends the response and keeps a copy of its body.
+type recordWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *recordWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
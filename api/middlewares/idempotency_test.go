@@ -0,0 +1,173 @@
+package middlewares
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Forum-service/Forum-api-gateway/api/authz"
+	"github.com/Forum-service/Forum-api-gateway/api/idempotency"
+	"github.com/gin-gonic/gin"
+)
+
+// idempotentPosts is a router with Idempotency on POST /posts. The X-User
+// request header sets the caller; a body of "fail" makes the handler fail.
+type idempotentPosts struct {
+	*gin.Engine
+	calls   atomic.Int32
+	entered chan struct{}
+	release chan struct{}
+}
+
+func newIdempotentPosts(ttl time.Duration) *idempotentPosts {
+	gin.SetMode(gin.TestMode)
+	r := &idempotentPosts{Engine: gin.New()}
+	r.Use(func(c *gin.Context) {
+		if user := c.GetHeader("X-User"); user != "" {
+			c.Set(PrincipalKey, authz.Principal{UserID: user})
+		}
+	}, Idempotency(idempotency.NewMemoryStore(), ttl))
+	r.POST("/posts", func(c *gin.Context) {
+		n := r.calls.Add(1)
+		if r.entered != nil {
+			r.entered <- struct{}{}
+			<-r.release
+		}
+		body, _ := io.ReadAll(c.Request.Body)
+		if string(body) == "fail" {
+			c.String(http.StatusInternalServerError, "failed")
+			return
+		}
+		c.Header("Location", fmt.Sprintf("/posts/%d", n))
+		c.String(http.StatusCreated, "created %d from %s", n, body)
+	})
+	return r
+}
+
+func (r *idempotentPosts) post(key, user, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/posts", strings.NewReader(body))
+	if key != "" {
+		req.Header.Set("Idempotency-Key", key)
+	}
+	if user != "" {
+		req.Header.Set("X-User", user)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotencyReplaysResponse(t *testing.T) {
+	r := newIdempotentPosts(time.Hour)
+	first := r.post("k1", "u1", "hello")
+	retry := r.post("k1", "u1", "hello")
+
+	if first.Code != http.StatusCreated || first.Header().Get("Idempotent-Replayed") != "" {
+		t.Fatalf("first: status %d, replayed %q", first.Code, first.Header().Get("Idempotent-Replayed"))
+	}
+	if retry.Code != http.StatusCreated || retry.Header().Get("Idempotent-Replayed") != "true" {
+		t.Errorf("retry: status %d, replayed %q, want 201 replayed", retry.Code, retry.Header().Get("Idempotent-Replayed"))
+	}
+	if retry.Body.String() != first.Body.String() || retry.Header().Get("Location") != first.Header().Get("Location") {
+		t.Errorf("retry got %q at %q, want %q at %q", retry.Body, retry.Header().Get("Location"), first.Body, first.Header().Get("Location"))
+	}
+	if n := r.calls.Load(); n != 1 {
+		t.Errorf("handler calls = %d, want 1", n)
+	}
+
+	if r.post("", "u1", "hello"); r.calls.Load() != 2 {
+		t.Error("request without a key was deduplicated")
+	}
+}
+
+func TestIdempotencyRejectsReusedKey(t *testing.T) {
+	r := newIdempotentPosts(time.Hour)
+	r.post("k1", "u1", "hello")
+	w := r.post("k1", "u1", "goodbye")
+
+	if w.Code != http.StatusConflict {
+		t.Errorf("reused key with another body: status %d, want 409", w.Code)
+	}
+	if n := r.calls.Load(); n != 1 {
+		t.Errorf("handler calls = %d, want 1", n)
+	}
+}
+
+func TestIdempotencyRejectsConcurrentRetry(t *testing.T) {
+	r := newIdempotentPosts(time.Hour)
+	r.entered, r.release = make(chan struct{}), make(chan struct{})
+
+	first := make(chan *httptest.ResponseRecorder)
+	go func() { first <- r.post("k1", "u1", "hello") }()
+	<-r.entered
+
+	w := r.post("k1", "u1", "hello")
+	if w.Code != http.StatusTooEarly || w.Header().Get("Retry-After") == "" {
+		t.Errorf("retry while in flight: status %d, Retry-After %q, want 425 with Retry-After", w.Code, w.Header().Get("Retry-After"))
+	}
+
+	close(r.release)
+	if w := <-first; w.Code != http.StatusCreated {
+		t.Fatalf("first: status %d", w.Code)
+	}
+	r.entered = nil
+	if w := r.post("k1", "u1", "hello"); w.Header().Get("Idempotent-Replayed") != "true" {
+		t.Error("retry after the first request finished was not replayed")
+	}
+}
+
+func TestIdempotencyDoesNotStoreFailures(t *testing.T) {
+	r := newIdempotentPosts(time.Hour)
+	if w := r.post("k1", "u1", "fail"); w.Code != http.StatusInternalServerError {
+		t.Fatalf("status %d, want 500", w.Code)
+	}
+	w := r.post("k1", "u1", "fail")
+	if w.Header().Get("Idempotent-Replayed") != "" || r.calls.Load() != 2 {
+		t.Errorf("retry of a failed request was replayed, handler calls = %d", r.calls.Load())
+	}
+}
+
+func TestIdempotencyScopesKeysPerUser(t *testing.T) {
+	r := newIdempotentPosts(time.Hour)
+	r.post("k1", "u1", "hello")
+
+	w := r.post("k1", "u2", "hello")
+	if w.Code != http.StatusCreated || w.Header().Get("Idempotent-Replayed") != "" {
+		t.Errorf("same key from another user: status %d, replayed %q, want a new 201", w.Code, w.Header().Get("Idempotent-Replayed"))
+	}
+	if w := r.post("k1", "u2", "other"); w.Code != http.StatusConflict {
+		t.Errorf("reused key of the second user: status %d, want 409", w.Code)
+	}
+	if n := r.calls.Load(); n != 2 {
+		t.Errorf("handler calls = %d, want 2", n)
+	}
+}
+
+func TestIdempotencyKeyExpires(t *testing.T) {
+	r := newIdempotentPosts(50 * time.Millisecond)
+	r.post("k1", "u1", "hello")
+	if w := r.post("k1", "u1", "hello"); w.Header().Get("Idempotent-Replayed") != "true" {
+		t.Fatal("retry within the TTL was not replayed")
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	w := r.post("k1", "u1", "other")
+	if w.Code != http.StatusCreated || w.Header().Get("Idempotent-Replayed") != "" {
+		t.Errorf("key reused after the TTL: status %d, replayed %q, want a new 201", w.Code, w.Header().Get("Idempotent-Replayed"))
+	}
+}
+
+func TestIdempotencyRejectsLongKeys(t *testing.T) {
+	r := newIdempotentPosts(time.Hour)
+	if w := r.post(strings.Repeat("k", 256), "u1", "hello"); w.Code != http.StatusBadRequest {
+		t.Errorf("256 character key: status %d, want 400", w.Code)
+	}
+	if n := r.calls.Load(); n != 0 {
+		t.Errorf("handler calls = %d, want 0", n)
+	}
+}
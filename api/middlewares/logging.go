@@ -0,0 +1,105 @@
+package middlewares
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	// RequestIDHeader is the header carrying the request id in both
+	// directions.
+	RequestIDHeader = "X-Request-ID"
+	// RequestIDKey is the gin.Context key holding the request id.
+	RequestIDKey = "request_id"
+	// RequestIDMetadata is the gRPC metadata key the request id is sent
+	// upstream as.
+	RequestIDMetadata = "x-request-id"
+
+	maxRequestIDLen = 128
+)
+
+// RequestID accepts the client's X-Request-ID or generates one, echoes it
+// in the response, forwards it to upstream gRPC calls and attaches a
+// request scoped logger carrying the request id, route and trace id to the
+// request context. Use log.Ctx(c.Request.Context()) to log with it.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		c.Set(RequestIDKey, id)
+		c.Header(RequestIDHeader, id)
+
+		ctx := c.Request.Context()
+		logCtx := log.Logger.With().
+			Str("request_id", id).
+			Str("route", c.FullPath())
+		if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+			logCtx = logCtx.Str("trace_id", sc.TraceID().String())
+		}
+		logger := logCtx.Logger()
+
+		ctx = metadata.AppendToOutgoingContext(ctx, RequestIDMetadata, id)
+		c.Request = c.Request.WithContext(logger.WithContext(ctx))
+		c.Next()
+	}
+}
+
+// AccessLog writes one structured log line per request with its status and
+// latency, using the request logger set up by RequestID.
+func AccessLog() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		logger := zerolog.Ctx(c.Request.Context())
+		var event *zerolog.Event
+		switch {
+		case status >= 500:
+			event = logger.Error()
+		case status >= 400:
+			event = logger.Warn()
+		default:
+			event = logger.Info()
+		}
+		if errs := c.Errors.ByType(gin.ErrorTypePrivate); len(errs) > 0 {
+			event = event.Str("errors", errs.String())
+		}
+		event.
+			Str("method", c.Request.Method).
+			Str("path", c.Request.URL.Path).
+			Int("status", status).
+			Dur("latency_ms", time.Since(start)).
+			Int("bytes", c.Writer.Size()).
+			Str("client_ip", c.ClientIP()).
+			Str("user_agent", c.Request.UserAgent()).
+			Msg("request")
+	}
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	for _, r := range id {
+		if r < 0x21 || r > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
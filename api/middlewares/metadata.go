@@ -0,0 +1,35 @@
+package middlewares
+
+import (
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/metadata"
+)
+
+// IdempotencyKeyMetadata is the gRPC metadata key carrying the client's
+// Idempotency-Key header.
+const IdempotencyKeyMetadata = "idempotency-key"
+
+// forwardedHeaders maps request headers to the gRPC metadata keys they are
+// sent upstream as.
+var forwardedHeaders = map[string]string{
+	"Idempotency-Key": IdempotencyKeyMetadata,
+}
+
+// ForwardMetadata copies selected request headers into the outgoing gRPC
+// metadata of the request context, so handlers passing
+// c.Request.Context() to a client send them upstream.
+func ForwardMetadata() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var kv []string
+		for header, key := range forwardedHeaders {
+			if v := c.GetHeader(header); v != "" {
+				kv = append(kv, key, v)
+			}
+		}
+		if len(kv) > 0 {
+			ctx := metadata.AppendToOutgoingContext(c.Request.Context(), kv...)
+			c.Request = c.Request.WithContext(ctx)
+		}
+		c.Next()
+	}
+}
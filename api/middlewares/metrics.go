@@ -0,0 +1,32 @@
+package middlewares
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/Forum-service/Forum-api-gateway/api/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+// Metrics records request counts, latency and in-flight requests labelled
+// by the matched route template rather than the raw path. It must run
+// before gin.Recovery to see requests that panicked.
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		inFlight := metrics.HTTPInFlight.WithLabelValues(route)
+		inFlight.Inc()
+		defer inFlight.Dec()
+		start := time.Now()
+
+		c.Next()
+
+		status := strconv.Itoa(c.Writer.Status())
+		metrics.HTTPRequests.WithLabelValues(route, c.Request.Method, status).Inc()
+		metrics.HTTPDuration.WithLabelValues(route, c.Request.Method, status).Observe(time.Since(start).Seconds())
+	}
+}
@@ -1,58 +1,131 @@
 package middlewares
 
 import (
-	"log"
 	"net/http"
+	"strings"
 
+	"github.com/Forum-service/Forum-api-gateway/api/authz"
+	"github.com/Forum-service/Forum-api-gateway/api/response"
 	"github.com/Forum-service/Forum-api-gateway/api/tokens"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
 )
 
-func Auth(c *gin.Context) {
-	tokenString := c.GetHeader("Authorization")
-	log.Println(tokenString)
-	if tokenString == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Missing authorization header"})
-		c.Abort()
-		return
-	}
-	if len(tokenString) < 8 {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Wrong authorization header"})
-		c.Abort()
-		return
-	}
-	tokenString = tokenString[len("Bearer "):]
-	err := tokens.VerifyToken(tokenString)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
-		c.Abort()
-		return
+const (
+	// ClaimsKey is the gin.Context key holding the validated jwt.MapClaims.
+	ClaimsKey = "claims"
+	// PrincipalKey is the gin.Context key holding the caller's authz.Principal.
+	PrincipalKey = "principal"
+)
+
+// Auth rejects requests without a valid bearer token and stores the token
+// claims on the context under ClaimsKey.
+func Auth(v *tokens.Verifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenString, ok := bearerToken(c)
+		if !ok {
+			c.Header("WWW-Authenticate", `Bearer realm="forum"`)
+			response.AbortWithError(c, http.StatusUnauthorized, "Missing authorization header")
+			return
+		}
+		claims, err := v.Verify(tokenString)
+		if err != nil {
+			c.Header("WWW-Authenticate", `Bearer realm="forum", error="invalid_token"`)
+			response.AbortWithError(c, http.StatusUnauthorized, err.Error())
+			return
+		}
+		principal := authz.FromClaims(claims)
+		c.Set(ClaimsKey, claims)
+		c.Set(PrincipalKey, principal)
+		zerolog.Ctx(c.Request.Context()).UpdateContext(func(l zerolog.Context) zerolog.Context {
+			return l.Str("user_id", principal.UserID)
+		})
+		c.Next()
 	}
-	c.Next()
 }
 
-func Role(c *gin.Context) {
-	tokenString := c.GetHeader("Authorization")[len("Bearer "):]
-
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		return []byte("secret"), nil
-	})
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
-		c.Abort()
-		return
+// GetClaims returns the claims stored by Auth.
+func GetClaims(c *gin.Context) (jwt.MapClaims, bool) {
+	claims, ok := c.Get(ClaimsKey)
+	if !ok {
+		return nil, false
 	}
-	claims, ok := token.Claims.(jwt.MapClaims)
+	mc, ok := claims.(jwt.MapClaims)
+	return mc, ok
+}
+
+// GetPrincipal returns the principal stored by Auth.
+func GetPrincipal(c *gin.Context) (authz.Principal, bool) {
+	p, ok := c.Get(PrincipalKey)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
-		c.Abort()
-		return
+		return authz.Principal{}, false
 	}
-	if claims["username"] != "admin" {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication error"})
-		c.Abort()
-		return
+	principal, ok := p.(authz.Principal)
+	return principal, ok
+}
+
+func bearerToken(c *gin.Context) (string, bool) {
+	header := c.GetHeader("Authorization")
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// Authorize enforces the policy rule of the matched route. Callers without
+// a principal get 401; callers lacking the required role, scope or
+// ownership get 403.
+func Authorize(policy *authz.Policy, owners authz.Owners) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rule, ok := policy.Rule(c.Request.Method, c.FullPath())
+		if !ok {
+			c.Next()
+			return
+		}
+
+		principal, ok := GetPrincipal(c)
+		if !ok {
+			response.AbortWithError(c, http.StatusUnauthorized, "Authentication required")
+			return
+		}
+		if len(rule.AnyOf) == 0 && rule.Owner == "" {
+			c.Next()
+			return
+		}
+		if principal.HasAny(rule.AnyOf) {
+			c.Next()
+			return
+		}
+
+		if rule.Owner != "" {
+			ownerOf, ok := owners[rule.Owner]
+			if !ok {
+				log.Ctx(c.Request.Context()).Error().Str("owner", rule.Owner).Str("route", c.FullPath()).Msg("no owner resolver registered")
+				response.AbortWithError(c, http.StatusForbidden, "Permission denied")
+				return
+			}
+			owner, err := ownerOf(c)
+			if err != nil {
+				log.Ctx(c.Request.Context()).Error().Err(err).Str("owner", rule.Owner).Msg("failed to resolve resource owner")
+				if !c.IsAborted() {
+					response.AbortWithGRPCError(c, err)
+				}
+				return
+			}
+			if owner != "" && owner == principal.UserID {
+				c.Next()
+				return
+			}
+		}
+
+		msg := "Permission denied"
+		if len(rule.AnyOf) > 0 {
+			msg += ": requires one of " + strings.Join(rule.AnyOf, ", ")
+		}
+		response.AbortWithError(c, http.StatusForbidden, msg)
 	}
-	c.Next()
 }
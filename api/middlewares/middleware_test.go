@@ -0,0 +1,90 @@
+package middlewares
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Forum-service/Forum-api-gateway/api/authz"
+	"github.com/Forum-service/Forum-api-gateway/api/response"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestAuthorize(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	policy, err := authz.ParsePolicy([]byte(`
+rules:
+  - method: PUT
+    path: /posts/:id
+    any_of: [forum:moderator]
+    owner: post
+  - method: DELETE
+    path: /posts/:id
+    any_of: [forum:admin]
+  - method: POST
+    path: /posts
+  - method: PUT
+    path: /tags/:id
+    owner: tag
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	owners := authz.Owners{
+		"post": func(c *gin.Context) (string, error) {
+			switch c.Param("id") {
+			case "missing":
+				return "", status.Error(codes.NotFound, "post not found")
+			case "unreadable":
+				response.AbortWithError(c, http.StatusBadRequest, "Failed to read request body")
+				return "", errors.New("unreadable body")
+			}
+			return "owner-" + c.Param("id"), nil
+		},
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		claims jwt.MapClaims
+		want   int
+	}{
+		{"no rule", "GET", "/posts/1", nil, http.StatusOK},
+		{"rule without principal", "POST", "/posts", nil, http.StatusUnauthorized},
+		{"rule with only authentication", "POST", "/posts", jwt.MapClaims{"sub": "u"}, http.StatusOK},
+		{"role", "PUT", "/posts/1", jwt.MapClaims{"sub": "u", "roles": []interface{}{"forum:moderator"}}, http.StatusOK},
+		{"scope", "PUT", "/posts/1", jwt.MapClaims{"sub": "u", "scope": "forum:moderator"}, http.StatusOK},
+		{"owner", "PUT", "/posts/1", jwt.MapClaims{"sub": "owner-1"}, http.StatusOK},
+		{"not owner", "PUT", "/posts/1", jwt.MapClaims{"sub": "owner-2"}, http.StatusForbidden},
+		{"owner without sub", "PUT", "/posts/1", jwt.MapClaims{}, http.StatusForbidden},
+		{"owner lookup fails", "PUT", "/posts/missing", jwt.MapClaims{"sub": "u"}, http.StatusNotFound},
+		{"owner lookup aborts", "PUT", "/posts/unreadable", jwt.MapClaims{"sub": "u"}, http.StatusBadRequest},
+		{"missing role", "DELETE", "/posts/1", jwt.MapClaims{"sub": "owner-1", "roles": []interface{}{"forum:moderator"}}, http.StatusForbidden},
+		{"no owner resolver", "PUT", "/tags/1", jwt.MapClaims{"sub": "u"}, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		r := gin.New()
+		r.Use(func(c *gin.Context) {
+			if tt.claims != nil {
+				c.Set(PrincipalKey, authz.FromClaims(tt.claims))
+			}
+		}, Authorize(policy, owners))
+		ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+		r.GET("/posts/:id", ok)
+		r.PUT("/posts/:id", ok)
+		r.DELETE("/posts/:id", ok)
+		r.POST("/posts", ok)
+		r.PUT("/tags/:id", ok)
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+		if w.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.name, w.Code, tt.want)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+// Package ratelimit implements token bucket rate limits kept in a
+// pluggable Store.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limit allows Burst requests at once, refilled at Rate requests per second.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// ParseLimit parses a limit written as <requests>/<period>, e.g. 100/1m.
+// The bucket holds the full period's worth of requests.
+func ParseLimit(s string) (Limit, error) {
+	n, period, ok := strings.Cut(strings.TrimSpace(s), "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("ratelimit: invalid limit %q, want <requests>/<period>", s)
+	}
+	requests, err := strconv.Atoi(strings.TrimSpace(n))
+	if err != nil || requests <= 0 {
+		return Limit{}, fmt.Errorf("ratelimit: invalid request count in %q", s)
+	}
+	d, err := time.ParseDuration(strings.TrimSpace(period))
+	if err != nil || d <= 0 {
+		return Limit{}, fmt.Errorf("ratelimit: invalid period in %q", s)
+	}
+	return Limit{Rate: float64(requests) / d.Seconds(), Burst: requests}, nil
+}
+
+// Window is the time an empty bucket takes to refill.
+func (l Limit) Window() time.Duration {
+	return secondsToDuration(float64(l.Burst) / l.Rate)
+}
+
+// Result is the outcome of taking a token.
+type Result struct {
+	Allowed   bool
+	Remaining int
+	// RetryAfter is how long to wait for the next token when not allowed.
+	RetryAfter time.Duration
+	// Reset is how long until the bucket is full again.
+	Reset time.Duration
+}
+
+// Store keeps the token buckets. Implementations must be safe for
+// concurrent use; a shared store such as Redis makes limits apply across
+// gateway replicas.
+type Store interface {
+	// Take removes one token from the bucket identified by key, creating
+	// a full bucket for limit if there is none.
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+func secondsToDuration(s float64) time.Duration {
+	return time.Duration(math.Ceil(s * float64(time.Second)))
+}
@@ -0,0 +1,73 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often idle buckets are dropped from a MemoryStore.
+const sweepInterval = time.Minute
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	limit  Limit
+}
+
+// MemoryStore is a Store local to one gateway process.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: map[string]*bucket{},
+		now:     time.Now,
+	}
+}
+
+// Take implements Store.
+func (s *MemoryStore) Take(_ context.Context, key string, limit Limit) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if now.Sub(s.lastSweep) >= sweepInterval {
+		s.sweep(now)
+	}
+
+	b, ok := s.buckets[key]
+	if !ok || b.limit != limit {
+		b = &bucket{tokens: float64(limit.Burst), last: now, limit: limit}
+		s.buckets[key] = b
+	}
+	b.tokens = math.Min(float64(limit.Burst), b.tokens+now.Sub(b.last).Seconds()*limit.Rate)
+	b.last = now
+
+	res := Result{Allowed: b.tokens >= 1}
+	if res.Allowed {
+		b.tokens--
+	} else {
+		res.RetryAfter = secondsToDuration((1 - b.tokens) / limit.Rate)
+	}
+	res.Remaining = int(b.tokens)
+	res.Reset = secondsToDuration((float64(limit.Burst) - b.tokens) / limit.Rate)
+	return res, nil
+}
+
+// sweep drops buckets that have refilled completely, which behave exactly
+// like missing ones. It must be called with s.mu held.
+func (s *MemoryStore) sweep(now time.Time) {
+	for key, b := range s.buckets {
+		if now.Sub(b.last) >= b.limit.Window() {
+			delete(s.buckets, key)
+		}
+	}
+	s.lastSweep = now
+}
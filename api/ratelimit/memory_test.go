@@ -0,0 +1,78 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryStoreTake(t *testing.T) {
+	now := time.Now()
+	s := NewMemoryStore()
+	s.now = func() time.Time { return now }
+	limit := Limit{Rate: 1, Burst: 3}
+	ctx := context.Background()
+
+	for i := 2; i >= 0; i-- {
+		res, err := s.Take(ctx, "k", limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !res.Allowed || res.Remaining != i {
+			t.Fatalf("Take = %+v, want allowed with %d remaining", res, i)
+		}
+	}
+
+	res, _ := s.Take(ctx, "k", limit)
+	if res.Allowed {
+		t.Fatal("Take on an empty bucket was allowed")
+	}
+	if res.RetryAfter != time.Second {
+		t.Errorf("RetryAfter = %v, want 1s", res.RetryAfter)
+	}
+	if res.Reset != 3*time.Second {
+		t.Errorf("Reset = %v, want 3s", res.Reset)
+	}
+
+	if res, _ := s.Take(ctx, "other", limit); !res.Allowed {
+		t.Error("Take on another key was not allowed")
+	}
+
+	now = now.Add(time.Second)
+	if res, _ := s.Take(ctx, "k", limit); !res.Allowed || res.Remaining != 0 {
+		t.Errorf("Take after one second = %+v, want allowed with 0 remaining", res)
+	}
+}
+
+func TestMemoryStoreRefillIsCapped(t *testing.T) {
+	now := time.Now()
+	s := NewMemoryStore()
+	s.now = func() time.Time { return now }
+	limit := Limit{Rate: 1, Burst: 2}
+	ctx := context.Background()
+
+	s.Take(ctx, "k", limit)
+	now = now.Add(time.Hour)
+	if res, _ := s.Take(ctx, "k", limit); res.Remaining != 1 {
+		t.Errorf("Remaining after a long idle period = %d, want 1", res.Remaining)
+	}
+}
+
+func TestMemoryStoreSweepsFullBuckets(t *testing.T) {
+	now := time.Now()
+	s := NewMemoryStore()
+	s.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	s.Take(ctx, "idle", Limit{Rate: 1, Burst: 1})
+	s.Take(ctx, "busy", Limit{Rate: 1.0 / 3600, Burst: 1})
+	now = now.Add(sweepInterval)
+	s.Take(ctx, "new", Limit{Rate: 1, Burst: 1})
+
+	if _, ok := s.buckets["idle"]; ok {
+		t.Error("refilled bucket was not swept")
+	}
+	if _, ok := s.buckets["busy"]; !ok {
+		t.Error("bucket still refilling was swept")
+	}
+}
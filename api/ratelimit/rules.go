@@ -0,0 +1,50 @@
+package ratelimit
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Rules selects the limit applied to a request. Routes are keyed by method
+// and route template, e.g. "POST /v1/comments", and get a bucket of their
+// own; other requests share the Read or Write bucket of the caller.
+type Rules struct {
+	Read   Limit
+	Write  Limit
+	Routes map[string]Limit
+}
+
+// ParseRules builds Rules from limits written as accepted by ParseLimit.
+func ParseRules(read, write string, routes map[string]string) (Rules, error) {
+	var (
+		r   = Rules{Routes: make(map[string]Limit, len(routes))}
+		err error
+	)
+	if r.Read, err = ParseLimit(read); err != nil {
+		return Rules{}, err
+	}
+	if r.Write, err = ParseLimit(write); err != nil {
+		return Rules{}, err
+	}
+	for route, s := range routes {
+		l, err := ParseLimit(s)
+		if err != nil {
+			return Rules{}, fmt.Errorf("%v for route %q", err, route)
+		}
+		r.Routes[route] = l
+	}
+	return r, nil
+}
+
+// For returns the bucket scope and limit for a request.
+func (r Rules) For(method, route string) (string, Limit) {
+	key := method + " " + route
+	if l, ok := r.Routes[key]; ok {
+		return key, l
+	}
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return "read", r.Read
+	}
+	return "write", r.Write
+}
@@ -0,0 +1,65 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Limit
+		wantErr bool
+	}{
+		{in: "100/1m", want: Limit{Rate: 100.0 / 60, Burst: 100}},
+		{in: " 10 / 1s ", want: Limit{Rate: 10, Burst: 10}},
+		{in: "100", wantErr: true},
+		{in: "0/1m", wantErr: true},
+		{in: "-1/1m", wantErr: true},
+		{in: "10/0s", wantErr: true},
+		{in: "10/minute", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseLimit(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseLimit(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseLimit(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+
+	if w := (Limit{Rate: 100.0 / 60, Burst: 100}).Window(); w != time.Minute {
+		t.Errorf("Window = %v, want 1m", w)
+	}
+}
+
+func TestRulesFor(t *testing.T) {
+	rules, err := ParseRules("300/1m", "60/1m", map[string]string{"POST /v1/posts": "10/1m"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		method, route string
+		scope         string
+		burst         int
+	}{
+		{"GET", "/v1/posts", "read", 300},
+		{"HEAD", "/v1/posts", "read", 300},
+		{"POST", "/v1/comments", "write", 60},
+		{"DELETE", "/v1/posts/:id", "write", 60},
+		{"POST", "/v1/posts", "POST /v1/posts", 10},
+		{"GET", "/v1/posts/:id", "read", 300},
+	}
+	for _, tt := range tests {
+		scope, limit := rules.For(tt.method, tt.route)
+		if scope != tt.scope || limit.Burst != tt.burst {
+			t.Errorf("For(%s %s) = %q, %d; want %q, %d", tt.method, tt.route, scope, limit.Burst, tt.scope, tt.burst)
+		}
+	}
+
+	if _, err := ParseRules("300/1m", "60/1m", map[string]string{"POST /v1/posts": "fast"}); err == nil {
+		t.Error("ParseRules accepted an invalid route limit")
+	}
+}
@@ -0,0 +1,182 @@
+package response
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// StatusClientClosedRequest is the non-standard status used when the client
+// went away before the upstream call finished.
+const StatusClientClosedRequest = 499
+
+// ErrorResponse is the envelope returned for every failed request.
+type ErrorResponse struct {
+	Error ErrorBody `json:"error"`
+}
+
+// ErrorBody describes a single error.
+type ErrorBody struct {
+	Code      string            `json:"code" example:"NOT_FOUND"`
+	Message   string            `json:"message" example:"post not found"`
+	RequestID string            `json:"request_id,omitempty"`
+	Details   []json.RawMessage `json:"details,omitempty" swaggertype:"array,object"`
+}
+
+// grpcToHTTP maps gRPC status codes to HTTP status codes.
+var grpcToHTTP = map[codes.Code]int{
+	codes.OK:                 http.StatusOK,
+	codes.Canceled:           StatusClientClosedRequest,
+	codes.Unknown:            http.StatusInternalServerError,
+	codes.InvalidArgument:    http.StatusBadRequest,
+	codes.DeadlineExceeded:   http.StatusGatewayTimeout,
+	codes.NotFound:           http.StatusNotFound,
+	codes.AlreadyExists:      http.StatusConflict,
+	codes.PermissionDenied:   http.StatusForbidden,
+	codes.ResourceExhausted:  http.StatusTooManyRequests,
+	codes.FailedPrecondition: http.StatusBadRequest,
+	codes.Aborted:            http.StatusConflict,
+	codes.OutOfRange:         http.StatusBadRequest,
+	codes.Unimplemented:      http.StatusNotImplemented,
+	codes.Internal:           http.StatusInternalServerError,
+	codes.Unavailable:        http.StatusServiceUnavailable,
+	codes.DataLoss:           http.StatusInternalServerError,
+	codes.Unauthenticated:    http.StatusUnauthorized,
+}
+
+// httpToCode gives gateway-originated errors the same codes as upstream ones.
+var httpToCode = map[int]codes.Code{
+	http.StatusBadRequest:          codes.InvalidArgument,
+	http.StatusUnauthorized:        codes.Unauthenticated,
+	http.StatusForbidden:           codes.PermissionDenied,
+	http.StatusNotFound:            codes.NotFound,
+	http.StatusConflict:            codes.AlreadyExists,
+	http.StatusTooManyRequests:     codes.ResourceExhausted,
+	http.StatusInternalServerError: codes.Internal,
+	http.StatusNotImplemented:      codes.Unimplemented,
+	http.StatusServiceUnavailable:  codes.Unavailable,
+	http.StatusGatewayTimeout:      codes.DeadlineExceeded,
+}
+
+// HTTPStatus returns the HTTP status code matching a gRPC code.
+func HTTPStatus(code codes.Code) int {
+	if s, ok := grpcToHTTP[code]; ok {
+		return s
+	}
+	return http.StatusInternalServerError
+}
+
+// CodeName returns the canonical upper snake case name of a gRPC code,
+// e.g. NOT_FOUND for codes.NotFound.
+func CodeName(code codes.Code) string {
+	name := code.String()
+	var b strings.Builder
+	for i, r := range name {
+		if i > 0 && r >= 'A' && r <= 'Z' {
+			b.WriteByte('_')
+		}
+		b.WriteRune(r)
+	}
+	return strings.ToUpper(b.String())
+}
+
+// AbortWithError aborts the request with the given HTTP status and message.
+func AbortWithError(c *gin.Context, httpStatus int, message string) {
+	code, ok := httpToCode[httpStatus]
+	name := CodeName(code)
+	if !ok {
+		name = strings.ToUpper(strings.ReplaceAll(http.StatusText(httpStatus), " ", "_"))
+	}
+	c.AbortWithStatusJSON(httpStatus, ErrorResponse{
+		Error: ErrorBody{
+			Code:      name,
+			Message:   message,
+			RequestID: requestID(c),
+		},
+	})
+}
+
+// AbortWithGRPCError translates an error returned by an upstream gRPC call
+// into the matching HTTP status and aborts the request with it.
+func AbortWithGRPCError(c *gin.Context, err error) {
+	st := FromError(err)
+	setRetryAfter(c, st)
+	c.AbortWithStatusJSON(HTTPStatus(st.Code()), ErrorResponse{
+		Error: ErrorBody{
+			Code:      CodeName(st.Code()),
+			Message:   st.Message(),
+			RequestID: requestID(c),
+			Details:   decodeDetails(st),
+		},
+	})
+}
+
+// NewErrorBody describes an upstream error without aborting the request,
+// for responses that report the failure of a part of the document.
+func NewErrorBody(err error) ErrorBody {
+	st := FromError(err)
+	return ErrorBody{
+		Code:    CodeName(st.Code()),
+		Message: st.Message(),
+		Details: decodeDetails(st),
+	}
+}
+
+// FromError converts err to a gRPC status, recognising context errors that
+// did not come back from the wire.
+func FromError(err error) *status.Status {
+	if st, ok := status.FromError(err); ok {
+		return st
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return status.FromContextError(err)
+	}
+	return status.New(codes.Unknown, err.Error())
+}
+
+// decodeDetails renders google.rpc error details (BadRequest, ErrorInfo,
+// RetryInfo, ...) as JSON objects tagged with their @type.
+func decodeDetails(st *status.Status) []json.RawMessage {
+	anys := st.Proto().GetDetails()
+	if len(anys) == 0 {
+		return nil
+	}
+	details := make([]json.RawMessage, 0, len(anys))
+	for _, a := range anys {
+		b, err := protojson.Marshal(a)
+		if err != nil {
+			b, _ = json.Marshal(map[string]string{"@type": a.GetTypeUrl()})
+		}
+		details = append(details, b)
+	}
+	return details
+}
+
+// setRetryAfter sets the Retry-After header from a RetryInfo detail.
+func setRetryAfter(c *gin.Context, st *status.Status) {
+	for _, d := range st.Details() {
+		if info, ok := d.(*errdetails.RetryInfo); ok && info.GetRetryDelay() != nil {
+			secs := int(math.Ceil(info.GetRetryDelay().AsDuration().Seconds()))
+			if secs < 1 {
+				secs = 1
+			}
+			c.Header("Retry-After", strconv.Itoa(secs))
+			return
+		}
+	}
+}
+
+// requestID returns the id echoed in the X-Request-ID response header.
+func requestID(c *gin.Context) string {
+	return c.Writer.Header().Get("X-Request-ID")
+}
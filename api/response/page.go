@@ -0,0 +1,28 @@
+package response
+
+// Page is the envelope returned by every list endpoint.
+type Page struct {
+	Data       interface{} `json:"data"`
+	Pagination Pagination  `json:"pagination"`
+}
+
+// Pagination describes the position of a page in the full result set.
+type Pagination struct {
+	Page    int32 `json:"page" example:"1"`
+	Limit   int32 `json:"limit" example:"10"`
+	Total   int64 `json:"total" example:"42"`
+	HasNext bool  `json:"has_next" example:"true"`
+}
+
+// CursorPage is the envelope returned by list endpoints in cursor mode.
+type CursorPage struct {
+	Data       interface{}      `json:"data"`
+	Pagination CursorPagination `json:"pagination"`
+}
+
+// CursorPagination carries the opaque cursor of the following page.
+type CursorPagination struct {
+	Limit      int32  `json:"limit" example:"10"`
+	HasNext    bool   `json:"has_next" example:"true"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
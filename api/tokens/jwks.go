@@ -0,0 +1,308 @@
+package tokens
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// JWKSOptions configures a JWKS key source.
+type JWKSOptions struct {
+	// Client is used for http(s) sources. Defaults to a client with a 10s timeout.
+	Client *http.Client
+	// RefreshInterval is how long a fetched key set is used before the next
+	// lookup refreshes it in the background.
+	RefreshInterval time.Duration
+	// MinRefreshInterval rate limits fetches triggered by unknown key ids.
+	MinRefreshInterval time.Duration
+}
+
+// JWKS is a KeySource backed by a JSON Web Key Set published at a URL or
+// stored in a local file. Keys are cached by kid; an unknown kid triggers a
+// rate limited refresh and a failed refresh keeps the last good key set.
+// Symmetric (oct) keys are only accepted from local files, as a published
+// secret would let anyone sign tokens.
+type JWKS struct {
+	source string
+	opts   JWKSOptions
+	now    func() time.Time
+
+	mu          sync.RWMutex
+	keys        keySet
+	fetchedAt   time.Time
+	attemptedAt time.Time
+
+	refreshMu sync.Mutex
+}
+
+// keySet is a parsed JSON Web Key Set. Keys without a kid are kept apart
+// and only verify tokens that carry no kid either.
+type keySet struct {
+	byKid   map[string]interface{}
+	unnamed []interface{}
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	K   string `json:"k"`
+}
+
+// NewJWKS creates a JWKS key source and performs the initial fetch. The
+// returned source is usable even if the initial fetch fails; it will retry
+// on the next lookup.
+func NewJWKS(source string, opts JWKSOptions) (*JWKS, error) {
+	if source == "" {
+		return nil, errors.New("tokens: jwks source is required")
+	}
+	if opts.Client == nil {
+		opts.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	if opts.RefreshInterval <= 0 {
+		opts.RefreshInterval = time.Hour
+	}
+	if opts.MinRefreshInterval <= 0 {
+		opts.MinRefreshInterval = 30 * time.Second
+	}
+
+	j := &JWKS{
+		source: source,
+		opts:   opts,
+		now:    time.Now,
+	}
+	return j, j.Refresh(context.Background())
+}
+
+// Keys implements KeySource.
+func (j *JWKS) Keys(kid, alg string) ([]interface{}, error) {
+	j.mu.RLock()
+	stale := j.now().Sub(j.fetchedAt) > j.opts.RefreshInterval
+	keys := j.lookup(kid, alg)
+	j.mu.RUnlock()
+
+	switch {
+	case len(keys) == 0:
+		// The key set may have been rotated; refresh before giving up.
+		j.refreshIfDue()
+		j.mu.RLock()
+		keys = j.lookup(kid, alg)
+		j.mu.RUnlock()
+	case stale:
+		go j.refreshIfDue()
+	}
+
+	if len(keys) == 0 {
+		return nil, ErrNoVerificationKey
+	}
+	return keys, nil
+}
+
+// Refresh fetches the key set and replaces the cached keys on success.
+func (j *JWKS) Refresh(ctx context.Context) error {
+	j.refreshMu.Lock()
+	defer j.refreshMu.Unlock()
+	return j.refresh(ctx)
+}
+
+// refreshIfDue refreshes the key set unless a refresh was attempted within
+// MinRefreshInterval. The interval is checked again once refreshMu is held,
+// so lookups that queued behind a running refresh do not fetch again.
+func (j *JWKS) refreshIfDue() {
+	if !j.refreshAllowed() {
+		return
+	}
+	j.refreshMu.Lock()
+	defer j.refreshMu.Unlock()
+	if !j.refreshAllowed() {
+		return
+	}
+	if err := j.refresh(context.Background()); err != nil {
+		log.Warn().Err(err).Str("source", j.source).Msg("failed to refresh jwks, using last good key set")
+	}
+}
+
+// refresh must be called with j.refreshMu held.
+func (j *JWKS) refresh(ctx context.Context) error {
+	j.mu.Lock()
+	j.attemptedAt = j.now()
+	j.mu.Unlock()
+
+	data, err := j.fetch(ctx)
+	if err != nil {
+		return err
+	}
+	keys, err := parseJWKS(data, j.remote())
+	if err != nil {
+		return err
+	}
+
+	j.mu.Lock()
+	j.keys = keys
+	j.fetchedAt = j.now()
+	j.mu.Unlock()
+	return nil
+}
+
+// lookup must be called with j.mu held.
+func (j *JWKS) lookup(kid, alg string) []interface{} {
+	if kid != "" {
+		if key, ok := j.keys.byKid[kid]; ok && keyMatchesAlg(key, alg) {
+			return []interface{}{key}
+		}
+		return nil
+	}
+	var keys []interface{}
+	for _, key := range j.keys.byKid {
+		if keyMatchesAlg(key, alg) {
+			keys = append(keys, key)
+		}
+	}
+	for _, key := range j.keys.unnamed {
+		if keyMatchesAlg(key, alg) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func (j *JWKS) refreshAllowed() bool {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	return j.now().Sub(j.attemptedAt) >= j.opts.MinRefreshInterval
+}
+
+// remote reports whether the key set is fetched over http(s).
+func (j *JWKS) remote() bool {
+	return strings.HasPrefix(j.source, "http://") || strings.HasPrefix(j.source, "https://")
+}
+
+func (j *JWKS) fetch(ctx context.Context) ([]byte, error) {
+	if !j.remote() {
+		return os.ReadFile(strings.TrimPrefix(j.source, "file://"))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, j.source, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := j.opts.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching jwks: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error fetching jwks: unexpected status %s", resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
+
+// parseJWKS decodes a key set. Symmetric keys are skipped when the set was
+// fetched from a remote source, and so are keys reusing a kid.
+func parseJWKS(data []byte, remote bool) (keySet, error) {
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return keySet{}, fmt.Errorf("error decoding jwks: %v", err)
+	}
+
+	keys := keySet{byKid: make(map[string]interface{}, len(set.Keys))}
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		if remote && jwk.Kty == "oct" {
+			log.Warn().Str("kid", jwk.Kid).Msg("skipping symmetric jwk from a remote source")
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			log.Warn().Err(err).Str("kid", jwk.Kid).Msg("skipping jwk")
+			continue
+		}
+		switch _, dup := keys.byKid[jwk.Kid]; {
+		case jwk.Kid == "":
+			keys.unnamed = append(keys.unnamed, key)
+		case dup:
+			log.Warn().Str("kid", jwk.Kid).Msg("skipping jwk with a duplicate kid")
+		default:
+			keys.byKid[jwk.Kid] = key
+		}
+	}
+	if len(keys.byKid) == 0 && len(keys.unnamed) == 0 {
+		return keySet{}, errors.New("jwks contains no usable signing keys")
+	}
+	return keys, nil
+}
+
+func (k jsonWebKey) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("point is not on curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "oct":
+		return base64.RawURLEncoding.DecodeString(k.K)
+	}
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,320 @@
+package tokens
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// jwksServer serves the RSA keys it holds as a JWKS and counts fetches.
+type jwksServer struct {
+	*httptest.Server
+	fetches atomic.Int32
+
+	mu   sync.Mutex
+	keys map[string]*rsa.PublicKey
+	fail bool
+}
+
+func newJWKSServer(t *testing.T) *jwksServer {
+	s := &jwksServer{keys: map[string]*rsa.PublicKey{}}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.fetches.Add(1)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.fail {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		var set struct {
+			Keys []jsonWebKey `json:"keys"`
+		}
+		for kid, key := range s.keys {
+			set.Keys = append(set.Keys, rsaJWK(kid, key))
+		}
+		json.NewEncoder(w).Encode(set)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func rsaJWK(kid string, key *rsa.PublicKey) jsonWebKey {
+	return jsonWebKey{
+		Kty: "RSA",
+		Kid: kid,
+		Use: "sig",
+		N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func (s *jwksServer) addKey(t *testing.T, kid string) *rsa.PublicKey {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.mu.Lock()
+	s.keys[kid] = &key.PublicKey
+	s.mu.Unlock()
+	return &key.PublicKey
+}
+
+func (s *jwksServer) setFail(fail bool) {
+	s.mu.Lock()
+	s.fail = fail
+	s.mu.Unlock()
+}
+
+// clock is a settable time source safe for concurrent use.
+type clock struct {
+	mu sync.Mutex
+	t  time.Time
+}
+
+func (c *clock) now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.t
+}
+
+func (c *clock) advance(d time.Duration) {
+	c.mu.Lock()
+	c.t = c.t.Add(d)
+	c.mu.Unlock()
+}
+
+func newTestJWKS(t *testing.T, s *jwksServer) (*JWKS, *clock) {
+	j, err := NewJWKS(s.URL, JWKSOptions{
+		RefreshInterval:    time.Hour,
+		MinRefreshInterval: time.Minute,
+	})
+	if err != nil {
+		t.Fatalf("NewJWKS: %v", err)
+	}
+	c := &clock{t: time.Now()}
+	j.mu.Lock()
+	j.now = c.now
+	j.fetchedAt = c.now()
+	j.attemptedAt = c.now()
+	j.mu.Unlock()
+	return j, c
+}
+
+func TestJWKSCachesKeysByKid(t *testing.T) {
+	s := newJWKSServer(t)
+	want := s.addKey(t, "a")
+	j, _ := newTestJWKS(t, s)
+
+	for i := 0; i < 3; i++ {
+		keys, err := j.Keys("a", "RS256")
+		if err != nil {
+			t.Fatalf("Keys: %v", err)
+		}
+		if len(keys) != 1 || keys[0].(*rsa.PublicKey).N.Cmp(want.N) != 0 {
+			t.Fatalf("Keys returned %v, want the key of kid a", keys)
+		}
+	}
+	if n := s.fetches.Load(); n != 1 {
+		t.Errorf("fetches = %d, want 1", n)
+	}
+	if _, err := j.Keys("a", "HS256"); err != ErrNoVerificationKey {
+		t.Errorf("Keys with HS256 for an RSA key: err = %v, want ErrNoVerificationKey", err)
+	}
+}
+
+func TestJWKSRefreshesOnUnknownKid(t *testing.T) {
+	s := newJWKSServer(t)
+	s.addKey(t, "a")
+	j, c := newTestJWKS(t, s)
+
+	s.addKey(t, "b")
+	c.advance(time.Minute)
+	if _, err := j.Keys("b", "RS256"); err != nil {
+		t.Fatalf("Keys for rotated kid: %v", err)
+	}
+	if n := s.fetches.Load(); n != 2 {
+		t.Errorf("fetches = %d, want 2", n)
+	}
+}
+
+func TestJWKSRateLimitsRefreshes(t *testing.T) {
+	s := newJWKSServer(t)
+	s.addKey(t, "a")
+	j, c := newTestJWKS(t, s)
+	c.advance(time.Minute)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := j.Keys("unknown", "RS256"); err != ErrNoVerificationKey {
+				t.Errorf("Keys for unknown kid: err = %v, want ErrNoVerificationKey", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := s.fetches.Load(); n != 2 {
+		t.Fatalf("fetches after concurrent unknown kids = %d, want 2", n)
+	}
+
+	c.advance(30 * time.Second)
+	j.Keys("unknown", "RS256")
+	if n := s.fetches.Load(); n != 2 {
+		t.Fatalf("fetches within MinRefreshInterval = %d, want 2", n)
+	}
+
+	c.advance(30 * time.Second)
+	j.Keys("unknown", "RS256")
+	if n := s.fetches.Load(); n != 3 {
+		t.Fatalf("fetches after MinRefreshInterval = %d, want 3", n)
+	}
+}
+
+func TestJWKSKeepsLastGoodSet(t *testing.T) {
+	s := newJWKSServer(t)
+	s.addKey(t, "a")
+	j, c := newTestJWKS(t, s)
+
+	s.setFail(true)
+	c.advance(2 * time.Hour)
+	if _, err := j.Keys("a", "RS256"); err != nil {
+		t.Fatalf("Keys with a stale set and failing source: %v", err)
+	}
+	waitFor(t, func() bool { return s.fetches.Load() == 2 })
+	if _, err := j.Keys("a", "RS256"); err != nil {
+		t.Fatalf("Keys after failed refresh: %v", err)
+	}
+	if err := j.Refresh(context.Background()); err == nil {
+		t.Error("Refresh against a failing source returned no error")
+	}
+	if _, err := j.Keys("a", "RS256"); err != nil {
+		t.Fatalf("Keys after failed explicit refresh: %v", err)
+	}
+}
+
+func TestJWKSRefreshesStaleSetInBackground(t *testing.T) {
+	s := newJWKSServer(t)
+	s.addKey(t, "a")
+	j, c := newTestJWKS(t, s)
+
+	s.mu.Lock()
+	s.keys = map[string]*rsa.PublicKey{}
+	s.mu.Unlock()
+	s.addKey(t, "b")
+	c.advance(2 * time.Hour)
+
+	if _, err := j.Keys("a", "RS256"); err != nil {
+		t.Fatalf("Keys with a stale set: %v", err)
+	}
+	waitFor(t, func() bool {
+		_, err := j.Keys("b", "RS256")
+		return err == nil
+	})
+	if n := s.fetches.Load(); n != 2 {
+		t.Errorf("fetches = %d, want 2", n)
+	}
+}
+
+// keySetSources returns an http and a file source serving keys.
+func keySetSources(t *testing.T, keys ...jsonWebKey) (url, file string) {
+	data, err := json.Marshal(map[string][]jsonWebKey{"keys": keys})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(data)
+	}))
+	t.Cleanup(srv.Close)
+
+	file = filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(file, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return srv.URL, file
+}
+
+func TestJWKSAcceptsSymmetricKeysOnlyFromFiles(t *testing.T) {
+	secret := jsonWebKey{Kty: "oct", Kid: "s", K: base64.RawURLEncoding.EncodeToString([]byte("secret"))}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	url, file := keySetSources(t, secret, rsaJWK("r", &rsaKey.PublicKey))
+
+	remote, err := NewJWKS(url, JWKSOptions{})
+	if err != nil {
+		t.Fatalf("NewJWKS(%s): %v", url, err)
+	}
+	if _, err := remote.Keys("s", "HS256"); err != ErrNoVerificationKey {
+		t.Errorf("symmetric key from an http source: err = %v, want ErrNoVerificationKey", err)
+	}
+	if _, err := remote.Keys("r", "RS256"); err != nil {
+		t.Errorf("RSA key next to a rejected symmetric key: %v", err)
+	}
+
+	local, err := NewJWKS("file://"+file, JWKSOptions{})
+	if err != nil {
+		t.Fatalf("NewJWKS(file): %v", err)
+	}
+	if keys, err := local.Keys("s", "HS256"); err != nil || string(keys[0].([]byte)) != "secret" {
+		t.Errorf("symmetric key from a file: keys = %v, err = %v", keys, err)
+	}
+
+	onlySecret, _ := keySetSources(t, secret)
+	if _, err := NewJWKS(onlySecret, JWKSOptions{}); err == nil {
+		t.Error("NewJWKS accepted a remote set holding only a symmetric key")
+	}
+}
+
+func TestJWKSKeepsKeysWithoutKid(t *testing.T) {
+	var pub []*rsa.PublicKey
+	for i := 0; i < 4; i++ {
+		key, err := rsa.GenerateKey(rand.Reader, 1024)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pub = append(pub, &key.PublicKey)
+	}
+	url, _ := keySetSources(t,
+		rsaJWK("", pub[0]),
+		rsaJWK("", pub[1]),
+		rsaJWK("a", pub[2]),
+		rsaJWK("a", pub[3]),
+	)
+
+	j, err := NewJWKS(url, JWKSOptions{})
+	if err != nil {
+		t.Fatalf("NewJWKS: %v", err)
+	}
+	got, err := j.Keys("", "RS256")
+	if err != nil || len(got) != 3 {
+		t.Fatalf("Keys without kid = %d keys, %v, want both unnamed keys and a", len(got), err)
+	}
+	got, err = j.Keys("a", "RS256")
+	if err != nil || got[0].(*rsa.PublicKey).N.Cmp(pub[2].N) != 0 {
+		t.Errorf("Keys(a) = %v, %v, want the first key with kid a", got, err)
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
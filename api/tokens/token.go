@@ -1,47 +1,180 @@
 package tokens
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/golang-jwt/jwt"
 )
 
-var secretKey = []byte("secret")
+var (
+	ErrInvalidToken      = errors.New("invalid token")
+	ErrNoVerificationKey = errors.New("no key available to verify token")
+	ErrMissingExpiry     = errors.New("token has no expiry")
+	ErrTokenExpired      = errors.New("token is expired")
+	ErrTokenNotValidYet  = errors.New("token is not valid yet")
+	ErrTokenIssuedLater  = errors.New("token used before issued")
+	ErrInvalidIssuer     = errors.New("token issuer is not accepted")
+	ErrInvalidAudience   = errors.New("token audience is not accepted")
+)
 
-func VerifyToken(tokenString string) error {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		return secretKey, nil
-	})
+// supportedAlgorithms lists the signing methods a Verifier can be
+// configured to accept.
+var supportedAlgorithms = map[string]bool{
+	"HS256": true, "HS384": true, "HS512": true,
+	"RS256": true, "RS384": true, "RS512": true,
+	"ES256": true, "ES384": true, "ES512": true,
+}
 
-	if err != nil {
-		return err
-	}
+// KeySource resolves the keys that may have signed a token.
+type KeySource interface {
+	// Keys returns the candidate verification keys for the given key id
+	// and algorithm. kid may be empty.
+	Keys(kid, alg string) ([]interface{}, error)
+}
+
+// Options configures token validation.
+type Options struct {
+	// Algorithms is the allow-list of accepted signing methods.
+	Algorithms []string
+	// Issuer, when set, must match the iss claim.
+	Issuer string
+	// Audience, when set, must contain at least one value of the aud claim.
+	Audience []string
+	// ClockSkew is the leeway applied to exp, nbf and iat.
+	ClockSkew time.Duration
+}
+
+// Verifier validates signed tokens against a KeySource.
+type Verifier struct {
+	keys   KeySource
+	opts   Options
+	parser *jwt.Parser
+	now    func() time.Time
+}
 
-	if !token.Valid {
-		return fmt.Errorf("invalid token")
+// NewVerifier returns a Verifier that accepts only the configured algorithms.
+func NewVerifier(keys KeySource, opts Options) (*Verifier, error) {
+	if keys == nil {
+		return nil, errors.New("tokens: key source is required")
+	}
+	if len(opts.Algorithms) == 0 {
+		return nil, errors.New("tokens: at least one algorithm must be allowed")
+	}
+	for _, alg := range opts.Algorithms {
+		if !supportedAlgorithms[alg] {
+			return nil, fmt.Errorf("tokens: unsupported algorithm %q", alg)
+		}
 	}
 
-	return nil
+	return &Verifier{
+		keys: keys,
+		opts: opts,
+		parser: &jwt.Parser{
+			ValidMethods:         opts.Algorithms,
+			SkipClaimsValidation: true,
+		},
+		now: time.Now,
+	}, nil
 }
-func ExtractClaim(tokenStr string) (jwt.MapClaims, error) {
-	var (
-		token *jwt.Token
-		err   error
-	)
 
-	keyFunc := func(token *jwt.Token) (interface{}, error) {
-		return secretKey, nil
+// Verify checks the token signature and registered claims and returns the
+// token claims.
+func (v *Verifier) Verify(tokenString string) (jwt.MapClaims, error) {
+	unverified, _, err := v.parser.ParseUnverified(tokenString, jwt.MapClaims{})
+	if err != nil {
+		return nil, err
+	}
+	alg := unverified.Method.Alg()
+	if !v.allowed(alg) {
+		return nil, fmt.Errorf("signing method %v is invalid", alg)
 	}
-	token, err = jwt.Parse(tokenStr, keyFunc)
+	kid, _ := unverified.Header["kid"].(string)
+
+	keys, err := v.keys.Keys(kid, alg)
 	if err != nil {
 		return nil, err
 	}
 
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !(ok && token.Valid) {
-		return nil, errors.New("invalid token")
+	lastErr := ErrNoVerificationKey
+	for _, key := range keys {
+		if !keyMatchesAlg(key, alg) {
+			continue
+		}
+		token, err := v.parser.Parse(tokenString, func(*jwt.Token) (interface{}, error) {
+			return key, nil
+		})
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		claims, ok := token.Claims.(jwt.MapClaims)
+		if !(ok && token.Valid) {
+			return nil, ErrInvalidToken
+		}
+		if err := v.validateClaims(claims); err != nil {
+			return nil, err
+		}
+		return claims, nil
+	}
+
+	return nil, lastErr
+}
+
+func (v *Verifier) allowed(alg string) bool {
+	for _, a := range v.opts.Algorithms {
+		if a == alg {
+			return true
+		}
 	}
+	return false
+}
+
+func (v *Verifier) validateClaims(claims jwt.MapClaims) error {
+	now := v.now()
+	skew := v.opts.ClockSkew
 
-	return claims, nil
+	exp, ok := numericDate(claims, "exp")
+	if !ok {
+		return ErrMissingExpiry
+	}
+	if now.After(exp.Add(skew)) {
+		return ErrTokenExpired
+	}
+	if nbf, ok := numericDate(claims, "nbf"); ok && now.Add(skew).Before(nbf) {
+		return ErrTokenNotValidYet
+	}
+	if iat, ok := numericDate(claims, "iat"); ok && now.Add(skew).Before(iat) {
+		return ErrTokenIssuedLater
+	}
+
+	if v.opts.Issuer != "" && !claims.VerifyIssuer(v.opts.Issuer, true) {
+		return ErrInvalidIssuer
+	}
+	if len(v.opts.Audience) > 0 {
+		for _, aud := range v.opts.Audience {
+			if claims.VerifyAudience(aud, true) {
+				return nil
+			}
+		}
+		return ErrInvalidAudience
+	}
+
+	return nil
+}
+
+func numericDate(claims jwt.MapClaims, name string) (time.Time, bool) {
+	switch v := claims[name].(type) {
+	case float64:
+		return time.Unix(int64(v), 0), true
+	case json.Number:
+		n, err := v.Int64()
+		if err != nil {
+			return time.Time{}, false
+		}
+		return time.Unix(n, 0), true
+	}
+	return time.Time{}, false
 }
@@ -0,0 +1,201 @@
+package tokens
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+var testNow = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// keyList is a KeySource returning the same keys for every lookup.
+type keyList []interface{}
+
+func (k keyList) Keys(string, string) ([]interface{}, error) {
+	return k, nil
+}
+
+func newTestVerifier(t *testing.T, keys KeySource, opts Options) *Verifier {
+	t.Helper()
+	v, err := NewVerifier(keys, opts)
+	if err != nil {
+		t.Fatalf("NewVerifier: %v", err)
+	}
+	v.now = func() time.Time { return testNow }
+	return v
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, key interface{}, claims jwt.MapClaims) string {
+	t.Helper()
+	s, err := jwt.NewWithClaims(method, claims).SignedString(key)
+	if err != nil {
+		t.Fatalf("signing token: %v", err)
+	}
+	return s
+}
+
+// validClaims expire an hour after testNow.
+func validClaims() jwt.MapClaims {
+	return jwt.MapClaims{"sub": "u1", "exp": testNow.Add(time.Hour).Unix()}
+}
+
+func TestNewVerifierRejectsBadOptions(t *testing.T) {
+	if _, err := NewVerifier(nil, Options{Algorithms: []string{"HS256"}}); err == nil {
+		t.Error("NewVerifier accepted a nil key source")
+	}
+	if _, err := NewVerifier(keyList{}, Options{}); err == nil {
+		t.Error("NewVerifier accepted an empty algorithm list")
+	}
+	if _, err := NewVerifier(keyList{}, Options{Algorithms: []string{"none"}}); err == nil {
+		t.Error("NewVerifier accepted the none algorithm")
+	}
+}
+
+func TestVerifyAlgorithmAllowList(t *testing.T) {
+	secret := []byte("secret")
+	v := newTestVerifier(t, keyList{secret}, Options{Algorithms: []string{"HS256"}})
+
+	if _, err := v.Verify(sign(t, jwt.SigningMethodHS256, secret, validClaims())); err != nil {
+		t.Fatalf("Verify HS256: %v", err)
+	}
+	if _, err := v.Verify(sign(t, jwt.SigningMethodHS512, secret, validClaims())); err == nil {
+		t.Error("Verify accepted HS512 outside the allow-list")
+	}
+	none := sign(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, validClaims())
+	if _, err := v.Verify(none); err == nil {
+		t.Error("Verify accepted an unsigned token")
+	}
+	if _, err := v.Verify(sign(t, jwt.SigningMethodHS256, []byte("other"), validClaims())); err == nil {
+		t.Error("Verify accepted a token signed with another secret")
+	}
+}
+
+func TestVerifyPublicKeys(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := newTestVerifier(t, keyList{&rsaKey.PublicKey, &ecKey.PublicKey}, Options{
+		Algorithms: []string{"RS256", "ES256", "HS256"},
+	})
+
+	if _, err := v.Verify(sign(t, jwt.SigningMethodRS256, rsaKey, validClaims())); err != nil {
+		t.Errorf("Verify RS256: %v", err)
+	}
+	if _, err := v.Verify(sign(t, jwt.SigningMethodES256, ecKey, validClaims())); err != nil {
+		t.Errorf("Verify ES256: %v", err)
+	}
+
+	// An HS256 token keyed with the public key must not verify against it.
+	der, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+	forged := sign(t, jwt.SigningMethodHS256, pub, validClaims())
+	if _, err := v.Verify(forged); !errors.Is(err, ErrNoVerificationKey) {
+		t.Errorf("Verify of HS256 token keyed with the public key: err = %v, want ErrNoVerificationKey", err)
+	}
+}
+
+func TestKeyMatchesAlg(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 1024)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	tests := []struct {
+		name string
+		key  interface{}
+		alg  string
+		want bool
+	}{
+		{"secret HS", []byte("s"), "HS256", true},
+		{"secret RS", []byte("s"), "RS256", false},
+		{"rsa RS", &rsaKey.PublicKey, "RS384", true},
+		{"rsa HS", &rsaKey.PublicKey, "HS256", false},
+		{"rsa ES", &rsaKey.PublicKey, "ES256", false},
+		{"ec ES", &ecKey.PublicKey, "ES256", true},
+		{"ec HS", &ecKey.PublicKey, "HS256", false},
+		{"string HS", "s", "HS256", false},
+	}
+	for _, tt := range tests {
+		if got := keyMatchesAlg(tt.key, tt.alg); got != tt.want {
+			t.Errorf("%s: keyMatchesAlg = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestVerifyTimeClaims(t *testing.T) {
+	secret := []byte("secret")
+	v := newTestVerifier(t, keyList{secret}, Options{
+		Algorithms: []string{"HS256"},
+		ClockSkew:  time.Minute,
+	})
+	at := func(d time.Duration) int64 { return testNow.Add(d).Unix() }
+
+	tests := []struct {
+		name   string
+		claims jwt.MapClaims
+		want   error
+	}{
+		{"valid", jwt.MapClaims{"exp": at(time.Hour), "nbf": at(-time.Hour), "iat": at(-time.Hour)}, nil},
+		{"no exp", jwt.MapClaims{"sub": "u1"}, ErrMissingExpiry},
+		{"expired", jwt.MapClaims{"exp": at(-2 * time.Minute)}, ErrTokenExpired},
+		{"expired within skew", jwt.MapClaims{"exp": at(-30 * time.Second)}, nil},
+		{"not valid yet", jwt.MapClaims{"exp": at(time.Hour), "nbf": at(2 * time.Minute)}, ErrTokenNotValidYet},
+		{"nbf within skew", jwt.MapClaims{"exp": at(time.Hour), "nbf": at(30 * time.Second)}, nil},
+		{"issued later", jwt.MapClaims{"exp": at(time.Hour), "iat": at(2 * time.Minute)}, ErrTokenIssuedLater},
+		{"iat within skew", jwt.MapClaims{"exp": at(time.Hour), "iat": at(30 * time.Second)}, nil},
+	}
+	for _, tt := range tests {
+		_, err := v.Verify(sign(t, jwt.SigningMethodHS256, secret, tt.claims))
+		if !errors.Is(err, tt.want) {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestVerifyIssuerAndAudience(t *testing.T) {
+	secret := []byte("secret")
+	v := newTestVerifier(t, keyList{secret}, Options{
+		Algorithms: []string{"HS256"},
+		Issuer:     "https://auth.example.com",
+		Audience:   []string{"forum", "forum-admin"},
+	})
+	claims := func(iss string, aud interface{}) jwt.MapClaims {
+		c := validClaims()
+		c["iss"] = iss
+		if aud != nil {
+			c["aud"] = aud
+		}
+		return c
+	}
+
+	tests := []struct {
+		name   string
+		claims jwt.MapClaims
+		want   error
+	}{
+		{"string aud", claims("https://auth.example.com", "forum"), nil},
+		{"list aud", claims("https://auth.example.com", []string{"other", "forum-admin"}), nil},
+		{"wrong issuer", claims("https://evil.example.com", "forum"), ErrInvalidIssuer},
+		{"wrong aud", claims("https://auth.example.com", "other"), ErrInvalidAudience},
+		{"no aud", claims("https://auth.example.com", nil), ErrInvalidAudience},
+	}
+	for _, tt := range tests {
+		_, err := v.Verify(sign(t, jwt.SigningMethodHS256, secret, tt.claims))
+		if !errors.Is(err, tt.want) {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
@@ -1,14 +1,94 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"net/http"
+	"os/signal"
+	"syscall"
+	"time"
+
 	"github.com/Forum-service/Forum-api-gateway/api"
+	"github.com/Forum-service/Forum-api-gateway/api/handler"
+	"github.com/Forum-service/Forum-api-gateway/api/tracing"
 	"github.com/Forum-service/Forum-api-gateway/config"
+	"github.com/Forum-service/Forum-api-gateway/config/logger"
+	"github.com/rs/zerolog/log"
 )
 
 func main() {
 	cfg := config.Load()
-	r := api.NewEngine()
-	if err := r.Run(cfg.HTTPPort); err != nil {
+
+	logFile, err := logger.Setup(cfg.Log)
+	if err != nil {
+		panic(err)
+	}
+	if logFile != nil {
+		defer logFile.Close()
+	}
+
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
 		panic(err)
 	}
+
+	h, err := handler.NewHandler(cfg)
+	if err != nil {
+		panic(err)
+	}
+	r := api.NewEngine(cfg, h)
+
+	srv := &http.Server{
+		Addr:              cfg.HTTPPort,
+		Handler:           r,
+		ReadTimeout:       cfg.HTTPReadTimeout,
+		ReadHeaderTimeout: cfg.HTTPReadHeaderTimeout,
+		WriteTimeout:      cfg.HTTPWriteTimeout,
+		IdleTimeout:       cfg.HTTPIdleTimeout,
+		MaxHeaderBytes:    cfg.HTTPMaxHeaderBytes,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		log.Info().Str("addr", srv.Addr).Msg("starting http server")
+		serveErr <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			h.Close()
+			panic(err)
+		}
+	case <-ctx.Done():
+		stop()
+		shutdown(cfg, srv, h)
+	}
+
+	if err := shutdownTracing(context.Background()); err != nil {
+		log.Error().Err(err).Msg("failed to flush traces")
+	}
+}
+
+// shutdown marks the gateway as not ready, gives load balancers time to
+// notice, drains in-flight requests within the grace period and closes the
+// upstream connections.
+func shutdown(cfg config.Config, srv *http.Server, h *handler.Handler) {
+	log.Info().Msg("shutting down")
+	h.Drain()
+	time.Sleep(cfg.ShutdownDelay)
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownGracePeriod)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Error().Err(err).Msg("failed to drain in-flight requests")
+	}
+
+	if err := h.Close(); err != nil {
+		log.Error().Err(err).Msg("failed to close upstream connections")
+	}
+	log.Info().Msg("shutdown complete")
 }
@@ -3,46 +3,232 @@ package config
 import (
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/spf13/cast"
+	"gopkg.in/yaml.v3"
 )
 
 type Config struct {
-	HTTPPort string
+	HTTPPort              string
+	HTTPReadTimeout       time.Duration
+	HTTPReadHeaderTimeout time.Duration
+	HTTPWriteTimeout      time.Duration
+	HTTPIdleTimeout       time.Duration
+	HTTPMaxHeaderBytes    int
+	// TrustedProxies are the addresses or CIDRs allowed to set the client
+	// IP through X-Forwarded-For or X-Real-IP.
+	TrustedProxies []string
 
-	PostgresHost     string
-	PostgresPort     int
-	PostgresUser     string
-	PostgresPassword string
-	PostgresDatabase string
+	// ShutdownDelay is how long the gateway reports not ready before it
+	// stops accepting connections.
+	ShutdownDelay       time.Duration
+	ShutdownGracePeriod time.Duration
 
-	DefaultOffset string
-	DefaultLimit  string
+	CategoryService GRPCClientConfig
+	TagService      GRPCClientConfig
+	PostService     GRPCClientConfig
+	CommentService  GRPCClientConfig
+	PostTagService  GRPCClientConfig
+	SearchService   GRPCClientConfig
+
+	GRPCMethodTimeouts map[string]time.Duration
+	GRPCRetry          RetryConfig
+	Breaker            BreakerConfig
+
+	HealthCheckUpstream bool
+	ReadinessTimeout    time.Duration
+
+	Log       LogConfig
+	Tracing   TracingConfig
+	RateLimit RateLimitConfig
+	Cache     CacheConfig
+
+	Idempotency IdempotencyConfig
+
+	// DefaultLimit is the page size used when a list request has no limit;
+	// MaxLimit is the largest page size a client may ask for.
+	DefaultLimit int
+	MaxLimit     int
+	// CursorSecret signs the opaque cursors handed out for keyset
+	// pagination. It must be shared by all gateway replicas.
+	CursorSecret string
+
+	// CommentTreeDepth is the number of reply levels loaded in comment tree
+	// mode when the request does not ask for a depth, up to
+	// CommentTreeMaxDepth.
+	CommentTreeDepth    int
+	CommentTreeMaxDepth int
+
+	JWTSecret         string
+	JWTPublicKeyFiles []string
+	JWTAlgorithms     []string
+	JWTIssuer         string
+	JWTAudience       []string
+	JWTClockSkew      time.Duration
+
+	JWKSURL                string
+	JWKSRefreshInterval    time.Duration
+	JWKSMinRefreshInterval time.Duration
+
+	AuthzPolicyFile string
+	// IncludeDeletedRoles are the roles or scopes allowed to list soft
+	// deleted resources with include_deleted=true.
+	IncludeDeletedRoles []string
+	// RequireIfMatch rejects updates that do not send If-Match with 428.
+	RequireIfMatch bool
 }
 
-// Load ...
+// Load reads the configuration from environment variables, falling back to
+// the YAML file named by CONFIG_FILE and then to built-in defaults. The file
+// uses the environment variable names as keys, e.g. FORUM_SERVICE_ADDR.
 func Load() Config {
 	if err := godotenv.Load(".env"); err != nil {
 		fmt.Println("No .env file found", err)
 	}
+	if path, ok := os.LookupEnv("CONFIG_FILE"); ok {
+		if err := loadFile(path); err != nil {
+			fmt.Println("Error loading config file", err)
+		}
+	}
 
 	config := Config{}
 
 	config.HTTPPort = cast.ToString(getOrReturnDefaultValue("HTTP_PORT", ":8080"))
+	config.HTTPReadTimeout = cast.ToDuration(getOrReturnDefaultValue("HTTP_READ_TIMEOUT", "15s"))
+	config.HTTPReadHeaderTimeout = cast.ToDuration(getOrReturnDefaultValue("HTTP_READ_HEADER_TIMEOUT", "5s"))
+	config.HTTPWriteTimeout = cast.ToDuration(getOrReturnDefaultValue("HTTP_WRITE_TIMEOUT", "30s"))
+	config.HTTPIdleTimeout = cast.ToDuration(getOrReturnDefaultValue("HTTP_IDLE_TIMEOUT", "60s"))
+	config.HTTPMaxHeaderBytes = cast.ToInt(getOrReturnDefaultValue("HTTP_MAX_HEADER_BYTES", 1<<20))
+	config.TrustedProxies = splitList(cast.ToString(getOrReturnDefaultValue("TRUSTED_PROXIES", "")))
+
+	config.ShutdownDelay = cast.ToDuration(getOrReturnDefaultValue("SHUTDOWN_DELAY", "5s"))
+	config.ShutdownGracePeriod = cast.ToDuration(getOrReturnDefaultValue("SHUTDOWN_GRACE_PERIOD", "30s"))
+
+	forum := loadGRPCClient("FORUM_SERVICE", GRPCClientConfig{
+		Address:          "forum_service:8082",
+		KeepaliveTime:    30 * time.Second,
+		KeepaliveTimeout: 10 * time.Second,
+		MaxRecvMsgSize:   4 << 20,
+		MaxSendMsgSize:   4 << 20,
+		Timeout:          5 * time.Second,
+	})
+	config.CategoryService = loadGRPCClient("CATEGORY_SERVICE", forum)
+	config.TagService = loadGRPCClient("TAG_SERVICE", forum)
+	config.PostService = loadGRPCClient("POST_SERVICE", forum)
+	config.CommentService = loadGRPCClient("COMMENT_SERVICE", forum)
+	config.PostTagService = loadGRPCClient("POSTTAG_SERVICE", forum)
+	config.SearchService = loadGRPCClient("SEARCH_SERVICE", forum)
+
+	config.GRPCMethodTimeouts = map[string]time.Duration{}
+	for method, timeout := range splitMap(cast.ToString(getOrReturnDefaultValue("GRPC_METHOD_TIMEOUTS", ""))) {
+		config.GRPCMethodTimeouts[method] = cast.ToDuration(timeout)
+	}
+	config.GRPCRetry.MaxAttempts = cast.ToInt(getOrReturnDefaultValue("GRPC_RETRY_MAX_ATTEMPTS", 3))
+	config.GRPCRetry.InitialBackoff = cast.ToDuration(getOrReturnDefaultValue("GRPC_RETRY_INITIAL_BACKOFF", "100ms"))
+	config.GRPCRetry.MaxBackoff = cast.ToDuration(getOrReturnDefaultValue("GRPC_RETRY_MAX_BACKOFF", "2s"))
+	config.GRPCRetry.Multiplier = cast.ToFloat64(getOrReturnDefaultValue("GRPC_RETRY_BACKOFF_MULTIPLIER", 2.0))
+
+	config.Breaker.Enabled = cast.ToBool(getOrReturnDefaultValue("BREAKER_ENABLED", true))
+	config.Breaker.FailureRatio = cast.ToFloat64(getOrReturnDefaultValue("BREAKER_FAILURE_RATIO", 0.5))
+	config.Breaker.MinRequests = cast.ToInt(getOrReturnDefaultValue("BREAKER_MIN_REQUESTS", 20))
+	config.Breaker.Interval = cast.ToDuration(getOrReturnDefaultValue("BREAKER_INTERVAL", "60s"))
+	config.Breaker.CoolDown = cast.ToDuration(getOrReturnDefaultValue("BREAKER_COOL_DOWN", "30s"))
+	config.Breaker.HalfOpenMaxRequests = cast.ToInt(getOrReturnDefaultValue("BREAKER_HALF_OPEN_MAX_REQUESTS", 3))
+
+	config.HealthCheckUpstream = cast.ToBool(getOrReturnDefaultValue("HEALTH_CHECK_UPSTREAM", false))
+	config.ReadinessTimeout = cast.ToDuration(getOrReturnDefaultValue("READINESS_TIMEOUT", "2s"))
+
+	config.Log.Level = cast.ToString(getOrReturnDefaultValue("LOG_LEVEL", "info"))
+	config.Log.Format = cast.ToString(getOrReturnDefaultValue("LOG_FORMAT", "json"))
+	config.Log.Output = cast.ToString(getOrReturnDefaultValue("LOG_OUTPUT", "stdout"))
+	config.Log.MaxSizeMB = cast.ToInt(getOrReturnDefaultValue("LOG_FILE_MAX_SIZE_MB", 100))
+	config.Log.MaxBackups = cast.ToInt(getOrReturnDefaultValue("LOG_FILE_MAX_BACKUPS", 5))
+	config.Log.MaxAgeDays = cast.ToInt(getOrReturnDefaultValue("LOG_FILE_MAX_AGE_DAYS", 30))
+	config.Log.Compress = cast.ToBool(getOrReturnDefaultValue("LOG_FILE_COMPRESS", true))
+
+	config.Tracing.Exporter = cast.ToString(getOrReturnDefaultValue("TRACING_EXPORTER", "none"))
+	config.Tracing.ServiceName = cast.ToString(getOrReturnDefaultValue("TRACING_SERVICE_NAME", "forum-api-gateway"))
+	config.Tracing.SampleRatio = cast.ToFloat64(getOrReturnDefaultValue("TRACING_SAMPLE_RATIO", 1.0))
+	config.Tracing.OTLPEndpoint = cast.ToString(getOrReturnDefaultValue("TRACING_OTLP_ENDPOINT", "localhost:4317"))
+	config.Tracing.OTLPInsecure = cast.ToBool(getOrReturnDefaultValue("TRACING_OTLP_INSECURE", true))
+	config.Tracing.File = cast.ToString(getOrReturnDefaultValue("TRACING_FILE", "traces.json"))
+
+	config.RateLimit.Enabled = cast.ToBool(getOrReturnDefaultValue("RATE_LIMIT_ENABLED", true))
+	config.RateLimit.Read = cast.ToString(getOrReturnDefaultValue("RATE_LIMIT_READ", "300/1m"))
+	config.RateLimit.Write = cast.ToString(getOrReturnDefaultValue("RATE_LIMIT_WRITE", "60/1m"))
+	config.RateLimit.Client = cast.ToString(getOrReturnDefaultValue("RATE_LIMIT_CLIENT", "600/1m"))
+	config.RateLimit.Routes = splitMap(cast.ToString(getOrReturnDefaultValue("RATE_LIMIT_ROUTES", "POST /v1/posts=10/1m,POST /v1/comments=30/1m")))
+	config.RateLimit.APIKeyHeader = cast.ToString(getOrReturnDefaultValue("RATE_LIMIT_API_KEY_HEADER", "X-API-Key"))
+
+	config.Cache.Enabled = cast.ToBool(getOrReturnDefaultValue("CACHE_ENABLED", true))
+	config.Cache.MaxEntries = cast.ToInt(getOrReturnDefaultValue("CACHE_MAX_ENTRIES", 10000))
+	config.Cache.TTLs = map[string]time.Duration{}
+	for route, ttl := range splitMap(cast.ToString(getOrReturnDefaultValue("CACHE_TTLS", "/v1/tags/popular=30s,/v1/categories=1m,/v1/posts/:id=30s"))) {
+		config.Cache.TTLs[route] = cast.ToDuration(ttl)
+	}
+
+	config.Idempotency.Enabled = cast.ToBool(getOrReturnDefaultValue("IDEMPOTENCY_ENABLED", true))
+	config.Idempotency.TTL = cast.ToDuration(getOrReturnDefaultValue("IDEMPOTENCY_TTL", "24h"))
+
+	config.DefaultLimit = cast.ToInt(getOrReturnDefaultValue("DEFAULT_LIMIT", 10))
+	config.MaxLimit = cast.ToInt(getOrReturnDefaultValue("MAX_LIMIT", 100))
+	config.CursorSecret = cast.ToString(getOrReturnDefaultValue("CURSOR_SECRET", ""))
+
+	config.CommentTreeDepth = cast.ToInt(getOrReturnDefaultValue("COMMENT_TREE_DEPTH", 3))
+	config.CommentTreeMaxDepth = cast.ToInt(getOrReturnDefaultValue("COMMENT_TREE_MAX_DEPTH", 10))
+
+	config.JWTSecret = cast.ToString(getOrReturnDefaultValue("JWT_SECRET", ""))
+	config.JWTPublicKeyFiles = splitList(cast.ToString(getOrReturnDefaultValue("JWT_PUBLIC_KEY_FILES", "")))
+	config.JWTAlgorithms = splitList(cast.ToString(getOrReturnDefaultValue("JWT_ALGORITHMS", "HS256")))
+	config.JWTIssuer = cast.ToString(getOrReturnDefaultValue("JWT_ISSUER", ""))
+	config.JWTAudience = splitList(cast.ToString(getOrReturnDefaultValue("JWT_AUDIENCE", "")))
+	config.JWTClockSkew = cast.ToDuration(getOrReturnDefaultValue("JWT_CLOCK_SKEW", "30s"))
 
-	config.PostgresHost = cast.ToString(getOrReturnDefaultValue("POSTGRES_HOST", "localhost"))
-	config.PostgresPort = cast.ToInt(getOrReturnDefaultValue("POSTGRES_PORT", 5432))
-	config.PostgresUser = cast.ToString(getOrReturnDefaultValue("POSTGRES_USER", "sayyidmuhammad"))
-	config.PostgresPassword = cast.ToString(getOrReturnDefaultValue("POSTGRES_PASSWORD", "root"))
-	config.PostgresDatabase = cast.ToString(getOrReturnDefaultValue("POSTGRES_DATABASE", "forum"))
+	config.JWKSURL = cast.ToString(getOrReturnDefaultValue("JWKS_URL", ""))
+	config.JWKSRefreshInterval = cast.ToDuration(getOrReturnDefaultValue("JWKS_REFRESH_INTERVAL", "1h"))
+	config.JWKSMinRefreshInterval = cast.ToDuration(getOrReturnDefaultValue("JWKS_MIN_REFRESH_INTERVAL", "30s"))
 
-	config.DefaultOffset = cast.ToString(getOrReturnDefaultValue("DEFAULT_OFFSET", "0"))
-	config.DefaultLimit = cast.ToString(getOrReturnDefaultValue("DEFAULT_LIMIT", "10"))
+	config.AuthzPolicyFile = cast.ToString(getOrReturnDefaultValue("AUTHZ_POLICY_FILE", ""))
+	config.IncludeDeletedRoles = splitList(cast.ToString(getOrReturnDefaultValue("INCLUDE_DELETED_ROLES", "forum:admin")))
+	config.RequireIfMatch = cast.ToBool(getOrReturnDefaultValue("REQUIRE_IF_MATCH", false))
 
 	return config
 }
 
+// splitMap parses a comma separated list of key=value pairs.
+func splitMap(val string) map[string]string {
+	m := map[string]string{}
+	for _, item := range splitList(val) {
+		k, v, ok := strings.Cut(item, "=")
+		if ok {
+			m[strings.TrimSpace(k)] = strings.TrimSpace(v)
+		}
+	}
+	return m
+}
+
+// fileValues holds the values read from the config file.
+var fileValues map[string]interface{}
+
+func loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	values := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	fileValues = make(map[string]interface{}, len(values))
+	for k, v := range values {
+		fileValues[strings.ToUpper(k)] = v
+	}
+	return nil
+}
+
 func getOrReturnDefaultValue(key string, defaultValue interface{}) interface{} {
 	val, exists := os.LookupEnv(key)
 
@@ -50,5 +236,20 @@ func getOrReturnDefaultValue(key string, defaultValue interface{}) interface{} {
 		return val
 	}
 
+	if val, exists := fileValues[key]; exists {
+		return val
+	}
+
 	return defaultValue
 }
+
+// splitList parses a comma separated value, dropping empty items.
+func splitList(val string) []string {
+	var items []string
+	for _, item := range strings.Split(val, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,127 @@
+package config
+
+import (
+	"time"
+
+	"github.com/spf13/cast"
+)
+
+// GRPCClientConfig describes how to reach an upstream gRPC service.
+type GRPCClientConfig struct {
+	Address string
+
+	TLS           bool
+	TLSCAFile     string
+	TLSCertFile   string
+	TLSKeyFile    string
+	TLSServerName string
+
+	KeepaliveTime                time.Duration
+	KeepaliveTimeout             time.Duration
+	KeepalivePermitWithoutStream bool
+
+	MaxRecvMsgSize int
+	MaxSendMsgSize int
+
+	// Timeout is the default deadline applied to calls without one.
+	Timeout time.Duration
+}
+
+// RetryConfig controls retries of idempotent upstream calls.
+type RetryConfig struct {
+	// MaxAttempts includes the first attempt; 1 disables retries.
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+}
+
+// BreakerConfig controls the circuit breaker kept for each upstream service.
+type BreakerConfig struct {
+	Enabled             bool
+	FailureRatio        float64
+	MinRequests         int
+	Interval            time.Duration
+	CoolDown            time.Duration
+	HalfOpenMaxRequests int
+}
+
+// LogConfig controls the application and access logs.
+type LogConfig struct {
+	Level string
+	// Format is json or console.
+	Format string
+	// Output is stdout, stderr or a file path.
+	Output     string
+	MaxSizeMB  int
+	MaxBackups int
+	MaxAgeDays int
+	Compress   bool
+}
+
+// RateLimitConfig sets the request limits, written as <requests>/<period>.
+type RateLimitConfig struct {
+	Enabled bool
+	// Read and Write apply to safe and unsafe methods respectively.
+	Read  string
+	Write string
+	// Client applies to every request of an API key or client IP and is
+	// checked before authentication.
+	Client string
+	// Routes overrides the limit of single routes, keyed by method and
+	// route template, e.g. "POST /v1/comments".
+	Routes       map[string]string
+	APIKeyHeader string
+}
+
+// CacheConfig controls the response cache of read endpoints.
+type CacheConfig struct {
+	Enabled    bool
+	MaxEntries int
+	// TTLs is keyed by route template, e.g. /v1/posts/:id.
+	TTLs map[string]time.Duration
+}
+
+// IdempotencyConfig controls the handling of Idempotency-Key headers.
+type IdempotencyConfig struct {
+	Enabled bool
+	// TTL is how long a key and its response are kept.
+	TTL time.Duration
+}
+
+// TracingConfig selects where spans are exported.
+type TracingConfig struct {
+	// Exporter is one of none, otlp, stdout or file.
+	Exporter     string
+	ServiceName  string
+	SampleRatio  float64
+	OTLPEndpoint string
+	OTLPInsecure bool
+	File         string
+}
+
+// loadGRPCClient reads the settings of one upstream from variables named
+// <prefix>_ADDR, <prefix>_TLS, <prefix>_TLS_CA_FILE and so on, using def for
+// anything not set.
+func loadGRPCClient(prefix string, def GRPCClientConfig) GRPCClientConfig {
+	c := GRPCClientConfig{}
+
+	c.Address = cast.ToString(getOrReturnDefaultValue(prefix+"_ADDR", def.Address))
+
+	c.TLS = cast.ToBool(getOrReturnDefaultValue(prefix+"_TLS", def.TLS))
+	c.TLSCAFile = cast.ToString(getOrReturnDefaultValue(prefix+"_TLS_CA_FILE", def.TLSCAFile))
+	c.TLSCertFile = cast.ToString(getOrReturnDefaultValue(prefix+"_TLS_CERT_FILE", def.TLSCertFile))
+	c.TLSKeyFile = cast.ToString(getOrReturnDefaultValue(prefix+"_TLS_KEY_FILE", def.TLSKeyFile))
+	c.TLSServerName = cast.ToString(getOrReturnDefaultValue(prefix+"_TLS_SERVER_NAME", def.TLSServerName))
+
+	c.KeepaliveTime = cast.ToDuration(getOrReturnDefaultValue(prefix+"_KEEPALIVE_TIME", def.KeepaliveTime))
+	c.KeepaliveTimeout = cast.ToDuration(getOrReturnDefaultValue(prefix+"_KEEPALIVE_TIMEOUT", def.KeepaliveTimeout))
+	c.KeepalivePermitWithoutStream = cast.ToBool(getOrReturnDefaultValue(prefix+"_KEEPALIVE_PERMIT_WITHOUT_STREAM", def.KeepalivePermitWithoutStream))
+
+	c.MaxRecvMsgSize = cast.ToInt(getOrReturnDefaultValue(prefix+"_MAX_RECV_MSG_SIZE", def.MaxRecvMsgSize))
+	c.MaxSendMsgSize = cast.ToInt(getOrReturnDefaultValue(prefix+"_MAX_SEND_MSG_SIZE", def.MaxSendMsgSize))
+
+	c.Timeout = cast.ToDuration(getOrReturnDefaultValue(prefix+"_TIMEOUT", def.Timeout))
+
+	return c
+}
@@ -1,32 +1,61 @@
 package logger
 
 import (
-	"log"
+	"fmt"
+	"io"
 	"os"
+	"strings"
+	"time"
+
+	"github.com/Forum-service/Forum-api-gateway/config"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/natefinch/lumberjack.v2"
 )
 
-type Logger struct {
-	INFO  *log.Logger
-	WARN  *log.Logger
-	ERROR *log.Logger
-	DEBUG *log.Logger
-	TRACE *log.Logger
-}
+// Setup configures the global zerolog logger from cfg. Output is stdout,
+// stderr or a file path; files are rotated by size and age. The returned
+// closer releases the log file and is nil when logging to stdout or stderr.
+func Setup(cfg config.LogConfig) (io.Closer, error) {
+	level, err := zerolog.ParseLevel(strings.ToLower(cfg.Level))
+	if err != nil {
+		return nil, fmt.Errorf("invalid log level %q: %v", cfg.Level, err)
+	}
 
-func NewLogger(basepath, path string) *Logger {
-	l := &Logger{}
+	var (
+		out    io.Writer
+		closer io.Closer
+	)
+	switch cfg.Output {
+	case "", "stdout":
+		out = os.Stdout
+	case "stderr":
+		out = os.Stderr
+	default:
+		file := &lumberjack.Logger{
+			Filename:   cfg.Output,
+			MaxSize:    cfg.MaxSizeMB,
+			MaxBackups: cfg.MaxBackups,
+			MaxAge:     cfg.MaxAgeDays,
+			Compress:   cfg.Compress,
+		}
+		out, closer = file, file
+	}
 
-	fullpath := basepath + "/" + path
-	var file, err = os.OpenFile(fullpath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		panic(err)
+	switch cfg.Format {
+	case "", "json":
+	case "console":
+		out = zerolog.ConsoleWriter{Out: out, TimeFormat: time.RFC3339, NoColor: cfg.Output != "" && cfg.Output != "stdout" && cfg.Output != "stderr"}
+	default:
+		return nil, fmt.Errorf("invalid log format %q", cfg.Format)
 	}
 
-	l.INFO = log.New(file, "[INFO]  ", log.Lshortfile|log.LstdFlags)
-	l.WARN = log.New(file, "[WARN]  ", log.Lshortfile|log.LstdFlags)
-	l.ERROR = log.New(file, "[ERROR]  ", log.Lshortfile|log.LstdFlags)
-	l.DEBUG = log.New(file, "[DEBUG]  ", log.Lshortfile|log.LstdFlags)
-	l.TRACE = log.New(file, "[TRACE]  ", log.Lshortfile|log.LstdFlags)
+	zerolog.SetGlobalLevel(level)
+	zerolog.TimeFieldFormat = time.RFC3339Nano
+	log.Logger = zerolog.New(out).With().Timestamp().Logger()
+	// Code logging through a context without a request logger falls back
+	// to the global one.
+	zerolog.DefaultContextLogger = &log.Logger
 
-	return l
+	return closer, nil
 }
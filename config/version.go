@@ -0,0 +1,9 @@
+package config
+
+// Build information, set at link time with
+// -ldflags "-X github.com/Forum-service/Forum-api-gateway/config.Version=...".
+var (
+	Version   = "dev"
+	Commit    = ""
+	BuildTime = ""
+)